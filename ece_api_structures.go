@@ -1,5 +1,116 @@
 package main
 
+// AllocatorOverview defines the overview of the allocators in ECE, grouped by zone.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#AllocatorOverview
+type AllocatorOverview struct {
+	Zones []AllocatorZoneInfo `json:"zones"`
+}
+
+// AllocatorZoneInfo defines information about an availability zone that contains allocators.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#AllocatorZoneInfo
+type AllocatorZoneInfo struct {
+	ZoneID string `json:"zone_id"`
+}
+
+// ApmConfiguration defines the APM Server settings. When specified at the top level, provides a field-by-field default.
+// When specified at the topology level, provides the override settings.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ApmConfiguration
+type ApmConfiguration struct {
+	SystemSettings           *ApmSystemSettings     `json:"system_settings,omitempty"`
+	UserSettingsJSON         map[string]interface{} `json:"user_settings_json,omitempty"`
+	UserSettingsOverrideJSON map[string]interface{} `json:"user_settings_override_json,omitempty"`
+	UserSettingsOverrideYAML string                 `json:"user_settings_override_yaml,omitempty"`
+	UserSettingsYAML         string                 `json:"user_settings_yaml,omitempty"`
+	Version                  string                 `json:"version,omitempty"`
+}
+
+// ApmCrudResponse defines the response to an APM cluster create request.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ApmCrudResponse
+type ApmCrudResponse struct {
+	ApmID       string `json:"apm_id"`
+	SecretToken string `json:"secret_token"`
+}
+
+// ApmInfo defines the information for an APM cluster.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ApmInfo
+type ApmInfo struct {
+	ElasticsearchCluster TargetElasticsearchCluster `json:"elasticsearch_cluster"`
+	Healthy              bool                       `json:"healthy"`
+	ID                   string                     `json:"id"`
+	Metadata             ClusterMetadataInfo        `json:"metadata"`
+	Name                 string                     `json:"name"`
+	PlanInfo             ApmPlansInfo               `json:"plan_info"`
+	Status               string                     `json:"status"`
+}
+
+// ApmPlan defines the plan for the APM cluster.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ApmPlan
+type ApmPlan struct {
+	Apm             ApmConfiguration     `json:"apm"`
+	ClusterTopology []ApmTopologyElement `json:"cluster_topology"`
+}
+
+// DefaultApmPlan returns a new ApmPlan with default values.
+func DefaultApmPlan() *ApmPlan {
+	return &ApmPlan{
+		ClusterTopology: []ApmTopologyElement{*DefaultApmTopologyElement()},
+	}
+}
+
+// ApmPlanInfo defines information about the current, pending, or past APM cluster plan.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ApmPlanInfo
+type ApmPlanInfo struct {
+	AttemptEndTime   string                `json:"attempt_end_time"`
+	AttemptStartTime string                `json:"attempt_start_time"`
+	Healthy          bool                  `json:"healthy"`
+	Plan             ApmPlan               `json:"plan"`
+	PlanAttemptID    string                `json:"plan_attempt_id"`
+	PlanAttemptLog   []ClusterPlanStepInfo `json:"plan_attempt_log"`
+	PlanAttemptName  string                `json:"plan_attempt_name"`
+	PlanEndTime      string                `json:"plan_end_time"`
+}
+
+// ApmPlansInfo defines information about the current, pending, or past APM cluster plans.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ApmPlansInfo
+type ApmPlansInfo struct {
+	Current ApmPlanInfo `json:"current"`
+	Healthy bool        `json:"healthy"`
+}
+
+// ApmSubInfo defines the information for an APM cluster that is associated with an Elasticsearch cluster.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ApmSubInfo
+type ApmSubInfo struct {
+	ApmID   string `json:"apm_id"`
+	Enabled bool   `json:"enabled"`
+}
+
+// ApmSystemSettings defines a subset of APM Server settings.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ApmSystemSettings
+type ApmSystemSettings struct {
+	SecretToken string `json:"secret_token,omitempty"`
+}
+
+// ApmTopologyElement defines the topology of the APM Server nodes, including the capacity of the nodes and where they
+// can be allocated.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ApmTopologyElement
+type ApmTopologyElement struct {
+	Apm                     *ApmConfiguration `json:"apm,omitempty"`
+	InstanceConfigurationID string            `json:"instance_configuration_id,omitempty"`
+	Size                    TopologySize      `json:"size"`
+	ZoneCount               int               `json:"zone_count"`
+}
+
+// DefaultApmTopologyElement returns a new ApmTopologyElement with default values.
+func DefaultApmTopologyElement() *ApmTopologyElement {
+	return &ApmTopologyElement{
+		Size: TopologySize{
+			Resource: "memory",
+			Value:    512,
+		},
+		ZoneCount: 1,
+	}
+}
+
 // ClusterCredentials defines the username and password for the new Elasticsearch cluster, which
 // is returned from the Elasticsearch cluster create command.
 // See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ClusterCredentials
@@ -17,15 +128,56 @@ type ClusterCrudResponse struct {
 	KibanaClusterID        string             `json:"kibana_cluster_id"`
 }
 
+// ClusterInstanceDiskInfo defines the disk capacity and usage of an instance.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ClusterInstanceDiskInfo
+type ClusterInstanceDiskInfo struct {
+	DiskSpaceAvailable int64 `json:"disk_space_available"`
+	DiskSpaceUsed      int64 `json:"disk_space_used"`
+}
+
 // ClusterInstanceInfo defines information about each instance in the Elasticsearch cluster.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ClusterInstanceInfo
 type ClusterInstanceInfo struct {
-	ServiceRoles []string `json:"service_roles"` // Currently only populated for Elasticsearch, with possible values: master,data,ingest,ml
+	AllocatorID     string                    `json:"allocator_id"`
+	Disk            ClusterInstanceDiskInfo   `json:"disk"`
+	Healthy         bool                      `json:"healthy"`
+	InstanceName    string                    `json:"instance_name"`
+	MaintenanceMode bool                      `json:"maintenance_mode"`
+	Memory          ClusterInstanceMemoryInfo `json:"memory"`
+	ServiceRoles    []string                  `json:"service_roles"` // Currently only populated for Elasticsearch, with possible values: master,data,ingest,ml
+	Zone            string                    `json:"zone"`
+}
+
+// ClusterInstanceMemoryInfo defines the memory capacity and pressure of an instance.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ClusterInstanceMemoryInfo
+type ClusterInstanceMemoryInfo struct {
+	InstanceCapacity int `json:"instance_capacity"`
+	MemoryPressure   int `json:"memory_pressure"`
+}
+
+// ClusterMetadataInfo defines the information about the cluster metadata, such as its endpoint and ports.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ClusterMetadataInfo
+type ClusterMetadataInfo struct {
+	AliasedEndpoint string                  `json:"aliased_endpoint"`
+	CloudID         string                  `json:"cloud_id"`
+	Endpoint        string                  `json:"endpoint"`
+	LastModified    string                  `json:"last_modified"`
+	Ports           ClusterMetadataPortInfo `json:"ports"`
+	Version         int                     `json:"version"`
+}
+
+// ClusterMetadataPortInfo defines the ports on which the cluster is available.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ClusterMetadataPortInfo
+type ClusterMetadataPortInfo struct {
+	HTTP  int `json:"http"`
+	HTTPS int `json:"https"`
 }
 
 // ClusterMetadataSettings defines the top-level configuration settings for the Elasticsearch cluster.
 // See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ClusterMetadataSettings
 type ClusterMetadataSettings struct {
-	ClusterName string `json:"name"`
+	ClusterName string          `json:"name"`
+	Tags        *[]MetadataItem `json:"tags,omitempty"`
 }
 
 // ClusterPlanStepInfo defines information about a step in a plan.
@@ -57,6 +209,14 @@ type ClusterTopologyInfo struct {
 	Instances []ClusterInstanceInfo `json:"instances"`
 }
 
+// CreateApmRequest defines the request body for creating an APM cluster.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#CreateApmRequest
+type CreateApmRequest struct {
+	ElasticsearchClusterID string   `json:"elasticsearch_cluster_id"`
+	Name                   string   `json:"name,omitempty"`
+	Plan                   *ApmPlan `json:"plan"`
+}
+
 // CreateElasticsearchClusterRequest defines the request body for creating an Elasticsearch cluster.
 // See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#CreateElasticsearchClusterRequest
 type CreateElasticsearchClusterRequest struct {
@@ -81,16 +241,51 @@ type CreateKibanaRequest struct {
 	Plan                   *KibanaClusterPlan `json:"plan"`
 }
 
+// CrossClusterSearchSettings defines the remote clusters that a cross-cluster search Elasticsearch cluster searches.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#CrossClusterSearchSettings
+type CrossClusterSearchSettings struct {
+	Remotes []RemoteClusterInfo `json:"remotes"`
+}
+
+// DeploymentTemplateDefinitionRequest defines the cluster template of a deployment template, which is used as
+// the basis for the plans of new clusters.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#DeploymentTemplateDefinitionRequest
+type DeploymentTemplateDefinitionRequest struct {
+	Kibana *CreateKibanaInCreateElasticsearchRequest `json:"kibana"`
+	Plan   ElasticsearchClusterPlan                  `json:"plan"`
+}
+
+// DeploymentTemplateInfo defines the information for a deployment template.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#DeploymentTemplateInfo
+type DeploymentTemplateInfo struct {
+	ClusterTemplate DeploymentTemplateDefinitionRequest `json:"cluster_template"`
+	Description     string                              `json:"description"`
+	ID              string                              `json:"id"`
+	Name            string                              `json:"name"`
+	SystemOwned     bool                                `json:"system_owned"`
+}
+
+// DiscreteSizes defines the sizes that are allowed for an instance configuration.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#DiscreteSizes
+type DiscreteSizes struct {
+	DefaultSize int    `json:"default_size"`
+	Resource    string `json:"resource"`
+	Sizes       []int  `json:"sizes"`
+}
+
 // ElasticsearchClusterInfo defines the information for an Elasticsearch cluster.
 // See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ElasticsearchClusterInfo
 type ElasticsearchClusterInfo struct {
-	ClusterID                string                        `json:"cluster_id"`
-	ClusterName              string                        `json:"cluster_name"`
-	Healthy                  bool                          `json:"healthy"`
-	PlanInfo                 ElasticsearchClusterPlansInfo `json:"plan_info"`
-	AssociatedKibanaClusters []KibanaSubClusterInfo        `json:"associated_kibana_clusters"`
-	Status                   string                        `json:"status"`
-	Topology                 ClusterTopologyInfo           `json:"topology"`
+	ClusterID                   string                        `json:"cluster_id"`
+	ClusterName                 string                        `json:"cluster_name"`
+	ElasticsearchMonitoringInfo *ElasticsearchMonitoringInfo  `json:"elasticsearch_monitoring_info,omitempty"`
+	Healthy                     bool                          `json:"healthy"`
+	Metadata                    ClusterMetadataInfo           `json:"metadata"`
+	PlanInfo                    ElasticsearchClusterPlansInfo `json:"plan_info"`
+	AssociatedApmClusters       []ApmSubInfo                  `json:"associated_apm_clusters"`
+	AssociatedKibanaClusters    []KibanaSubClusterInfo        `json:"associated_kibana_clusters"`
+	Status                      string                        `json:"status"`
+	Topology                    ClusterTopologyInfo           `json:"topology"`
 }
 
 // ElasticsearchClusterPlan defines the plan for an Elasticsearch cluster.
@@ -124,15 +319,28 @@ type ElasticsearchClusterPlansInfo struct {
 	Pending ElasticsearchClusterPlanInfo   `json:"pending"`
 }
 
+// ElasticsearchClusterRestartParams defines the parameters for restarting an Elasticsearch cluster. The parameters are
+// sent as query parameters rather than in the request body.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/restart-es-cluster.html
+type ElasticsearchClusterRestartParams struct {
+	CancelPending     bool
+	GroupAttribute    string
+	ShardInitWaitTime int
+	SkipSnapshot      bool
+}
+
 // ElasticsearchClusterTopologyElement defines the topology of the Elasticsearch nodes, including the number,
 // capacity, and type of nodes, and where they can be allocated.
 // See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ElasticsearchClusterTopologyElement
 type ElasticsearchClusterTopologyElement struct {
-	InstanceConfigurationID string                `json:"instance_configuration_id"`
-	MemoryPerNode           int                   `json:"memory_per_node"`
-	NodeCountPerZone        int                   `json:"node_count_per_zone"`
-	NodeType                ElasticsearchNodeType `json:"node_type"`
-	ZoneCount               int                   `json:"zone_count"`
+	AllocatorFilter         map[string]interface{}      `json:"allocator_filter,omitempty"`
+	Elasticsearch           *ElasticsearchConfiguration `json:"elasticsearch,omitempty"`
+	InstanceConfigurationID string                      `json:"instance_configuration_id"`
+	MemoryPerNode           int                         `json:"memory_per_node"`
+	NodeAttributes          map[string]string           `json:"node_attributes,omitempty"`
+	NodeCountPerZone        int                         `json:"node_count_per_zone"`
+	NodeType                ElasticsearchNodeType       `json:"node_type"`
+	ZoneCount               int                         `json:"zone_count"`
 }
 
 // DefaultElasticsearchClusterTopologyElement returns a new ElasticsearchClusterTopologyElement with default values.
@@ -146,11 +354,31 @@ func DefaultElasticsearchClusterTopologyElement() *ElasticsearchClusterTopologyE
 	}
 }
 
-// ElasticsearchConfiguration defines the Elasticsearch cluster settings.
+// ElasticsearchClustersInfo defines the information for a list of Elasticsearch clusters.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ElasticsearchClustersInfo
+type ElasticsearchClustersInfo struct {
+	ElasticsearchClusters []ElasticsearchClusterInfo `json:"elasticsearch_clusters"`
+	ReturnCount           int                        `json:"return_count"`
+}
+
+// ElasticsearchConfiguration defines the Elasticsearch cluster settings. When used in a topology element, the
+// settings override the plan-level settings.
 // See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ElasticsearchConfiguration
 type ElasticsearchConfiguration struct {
-	SystemSettings ElasticsearchSystemSettings `json:"system_settings"`
-	Version        string                      `json:"version"`
+	SystemSettings           *ElasticsearchSystemSettings `json:"system_settings,omitempty"`
+	UserSettingsJSON         map[string]interface{}       `json:"user_settings_json,omitempty"`
+	UserSettingsOverrideJSON map[string]interface{}       `json:"user_settings_override_json,omitempty"`
+	UserSettingsOverrideYAML string                       `json:"user_settings_override_yaml,omitempty"`
+	UserSettingsYAML         string                       `json:"user_settings_yaml,omitempty"`
+	Version                  string                       `json:"version,omitempty"`
+}
+
+// ElasticsearchMonitoringInfo defines the monitoring destinations and sources of an Elasticsearch cluster.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ElasticsearchMonitoringInfo
+type ElasticsearchMonitoringInfo struct {
+	DestinationClusterIDs []string `json:"destination_cluster_ids"`
+	Healthy               bool     `json:"healthy"`
+	SourceClusterIDs      []string `json:"source_cluster_ids"`
 }
 
 // ElasticsearchNodeType defines the combinations of Elasticsearch node types.
@@ -181,6 +409,20 @@ type ElasticsearchPlanControlConfiguration struct {
 	// Timeout int64 `json:"timeout"`
 }
 
+// ElasticsearchSnapshotInfo defines a subset of the information for an Elasticsearch snapshot, as returned by the
+// Elasticsearch snapshot API through the ECE cluster proxy.
+// See https://www.elastic.co/guide/en/elasticsearch/reference/current/modules-snapshots.html
+type ElasticsearchSnapshotInfo struct {
+	Reason   string `json:"reason,omitempty"`
+	Snapshot string `json:"snapshot"`
+	State    string `json:"state"`
+}
+
+// ElasticsearchSnapshotsInfo defines the list of snapshots returned by the Elasticsearch snapshot API.
+type ElasticsearchSnapshotsInfo struct {
+	Snapshots []ElasticsearchSnapshotInfo `json:"snapshots"`
+}
+
 // ElasticsearchSystemSettings defines a subset of elasticsearch settings.
 // See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ElasticsearchSystemSettings
 type ElasticsearchSystemSettings struct {
@@ -194,13 +436,41 @@ func DefaultElasticsearchSystemSettings() *ElasticsearchSystemSettings {
 	}
 }
 
+// InstanceConfiguration defines an instance configuration, which controls the allocation, allowed sizes, and
+// node types of topology elements.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#InstanceConfiguration
+type InstanceConfiguration struct {
+	DiscreteSizes DiscreteSizes `json:"discrete_sizes"`
+	ID            string        `json:"id"`
+	InstanceType  string        `json:"instance_type"`
+	Name          string        `json:"name"`
+	NodeTypes     []string      `json:"node_types"`
+}
+
+// KeystoreContents defines the secure settings in the keystore of an Elasticsearch cluster.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#KeystoreContents
+type KeystoreContents struct {
+	Secrets map[string]KeystoreSecret `json:"secrets"`
+}
+
+// KeystoreSecret defines a secure setting in the keystore of an Elasticsearch cluster. The value is either a string or
+// a JSON object, and is never returned by ECE. A secret without a value removes the setting from the keystore.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#KeystoreSecret
+type KeystoreSecret struct {
+	AsFile *bool       `json:"as_file,omitempty"`
+	Value  interface{} `json:"value,omitempty"`
+}
+
 // KibanaClusterInfo defines the top-level object information for a Kibana instance.
 // See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#KibanaClusterInfo
 type KibanaClusterInfo struct {
-	ClusterID   string `json:"cluster_id"`
-	ClusterName string `json:"cluster_name"`
-	Healthy     bool   `json:"healthy"`
-	Status      string `json:"status"`
+	ClusterID            string                     `json:"cluster_id"`
+	ClusterName          string                     `json:"cluster_name"`
+	ElasticsearchCluster TargetElasticsearchCluster `json:"elasticsearch_cluster"`
+	Healthy              bool                       `json:"healthy"`
+	Metadata             ClusterMetadataInfo        `json:"metadata"`
+	PlanInfo             KibanaClusterPlansInfo     `json:"plan_info"`
+	Status               string                     `json:"status"`
 }
 
 // KibanaClusterPlan defines the plan for the Kibana instance.
@@ -244,9 +514,11 @@ type KibanaClusterPlansInfo struct {
 // type of nodes, and where they can be allocated.
 // See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#KibanaClusterTopologyElement
 type KibanaClusterTopologyElement struct {
-	MemoryPerNode    int `json:"memory_per_node"`
-	NodeCountPerZone int `json:"node_count_per_zone"`
-	ZoneCount        int `json:"zone_count"`
+	InstanceConfigurationID string               `json:"instance_configuration_id,omitempty"`
+	Kibana                  *KibanaConfiguration `json:"kibana,omitempty"`
+	MemoryPerNode           int                  `json:"memory_per_node"`
+	NodeCountPerZone        int                  `json:"node_count_per_zone"`
+	ZoneCount               int                  `json:"zone_count"`
 }
 
 // DefaultKibanaClusterTopologyElement returns a new KibanaClusterTopologyElement with default values.
@@ -262,6 +534,12 @@ func DefaultKibanaClusterTopologyElement() *KibanaClusterTopologyElement {
 // When specified at the topology level, provides the override settings.
 // See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#KibanaConfiguration
 type KibanaConfiguration struct {
+	SystemSettings           *KibanaSystemSettings  `json:"system_settings,omitempty"`
+	UserSettingsJSON         map[string]interface{} `json:"user_settings_json,omitempty"`
+	UserSettingsOverrideJSON map[string]interface{} `json:"user_settings_override_json,omitempty"`
+	UserSettingsOverrideYAML string                 `json:"user_settings_override_yaml,omitempty"`
+	UserSettingsYAML         string                 `json:"user_settings_yaml,omitempty"`
+	Version                  string                 `json:"version,omitempty"`
 }
 
 // DefaultKibanaConfiguration returns a new KibanaConfiguration with default values.
@@ -276,6 +554,56 @@ type KibanaSubClusterInfo struct {
 	KibanaID string `json:"kibana_id"`
 }
 
+// KibanaSystemSettings defines a subset of Kibana settings.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#KibanaSystemSettings
+type KibanaSystemSettings struct {
+	ElasticsearchURL      string `json:"elasticsearch_url,omitempty"`
+	ElasticsearchUsername string `json:"elasticsearch_username,omitempty"`
+}
+
+// MetadataItem defines a key/value pair that is stored as a tag in the cluster metadata.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#MetadataItem
+type MetadataItem struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// RemoteClusterInfo defines a remote cluster of a cross-cluster search Elasticsearch cluster.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#RemoteClusterInfo
+type RemoteClusterInfo struct {
+	Alias           string `json:"alias"`
+	ElasticsearchID string `json:"elasticsearch_id"`
+	SkipUnavailable bool   `json:"skip_unavailable"`
+}
+
+// StackVersionConfig defines the configuration of an Elastic Stack version that is available in ECE.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#StackVersionConfig
+type StackVersionConfig struct {
+	Deleted           bool     `json:"deleted"`
+	MinUpgradableFrom string   `json:"min_upgradable_from"`
+	UpgradableTo      []string `json:"upgradable_to"`
+	Version           string   `json:"version"`
+}
+
+// StackVersionConfigs defines the Elastic Stack versions that are available in ECE.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#StackVersionConfigs
+type StackVersionConfigs struct {
+	Stacks []StackVersionConfig `json:"stacks"`
+}
+
+// TargetElasticsearchCluster defines the Elasticsearch cluster that is associated with a Kibana or APM cluster.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#TargetElasticsearchCluster
+type TargetElasticsearchCluster struct {
+	ElasticsearchID string `json:"elasticsearch_id"`
+}
+
+// TopologySize defines the size of a topology element, measured in the specified resource.
+// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#TopologySize
+type TopologySize struct {
+	Resource string `json:"resource"`
+	Value    int    `json:"value"`
+}
+
 // TransientElasticsearchPlanConfiguration defines the configuration parameters that control how the plan is applied.
 // For example, the Elasticsearch cluster topology and Elasticsearch settings.
 // See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#TransientElasticsearchPlanConfiguration
@@ -6,14 +6,22 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/terraform/helper/resource"
 )
 
+const allocatorsResource = "/api/v1/platform/infrastructure/allocators"
+const apmResource = "/api/v1/clusters/apm"
+const deploymentTemplatesResource = "/api/v1/platform/configuration/templates/deployments"
 const elasticsearchResource = "/api/v1/clusters/elasticsearch"
+const instanceConfigurationsResource = "/api/v1/platform/configuration/instances"
 const kibanaResource = "/api/v1/clusters/kibana"
+const stackVersionsResource = "/api/v1/stack/versions"
 const jsonContentType = "application/json"
 
 // ECEClient is a client used for interactions with the ECE API.
@@ -32,6 +40,126 @@ type ECEClient struct {
 
 	// Timeout in seconds for resource operations.
 	Timeout int
+
+	// ValidatePlans specifies whether cluster plan changes should be validated by the ECE API during terraform plan.
+	ValidatePlans bool
+
+	// deploymentTemplates caches the deployment templates read by ID, so that they are only read once.
+	deploymentTemplates     map[string][]byte
+	deploymentTemplatesLock sync.Mutex
+}
+
+// CancelElasticsearchClusterMonitoring stops sending the monitoring data of an existing elasticsearch cluster to its
+// monitoring cluster.
+func (c *ECEClient) CancelElasticsearchClusterMonitoring(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] CancelElasticsearchClusterMonitoring ID: %s\n", id)
+
+	// DELETE /api/v1/clusters/elasticsearch/{cluster_id}/monitoring
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/monitoring"
+	log.Printf("[DEBUG] CancelElasticsearchClusterMonitoring Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("DELETE", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] CancelElasticsearchClusterMonitoring response: %v\n", resp)
+
+	if resp.StatusCode != 200 && resp.StatusCode != 202 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster monitoring could not be cancelled: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// CancelElasticsearchClusterPendingPlan cancels the pending plan of an existing elasticsearch cluster. It is not an error
+// if the cluster has no pending plan.
+func (c *ECEClient) CancelElasticsearchClusterPendingPlan(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] CancelElasticsearchClusterPendingPlan ID: %s\n", id)
+
+	// DELETE /api/v1/clusters/elasticsearch/{cluster_id}/plan/pending
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/plan/pending"
+	log.Printf("[DEBUG] CancelElasticsearchClusterPendingPlan Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("DELETE", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] CancelElasticsearchClusterPendingPlan response: %v\n", resp)
+
+	// ECE responds with 404 or 412 when there is no pending plan to cancel.
+	if resp.StatusCode != 200 && resp.StatusCode != 404 && resp.StatusCode != 412 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster pending plan could not be cancelled: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// CreateApmCluster creates a new APM cluster using the specified create request.
+func (c *ECEClient) CreateApmCluster(createApmRequest CreateApmRequest) (crudResponse *ApmCrudResponse, err error) {
+	log.Printf("[DEBUG] CreateApmCluster: %v\n", createApmRequest)
+
+	jsonData, err := json.Marshal(createApmRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonString := string(jsonData)
+	log.Printf("[DEBUG] CreateApmCluster request body: %s\n", jsonString)
+
+	body := strings.NewReader(jsonString)
+
+	// POST /api/v1/clusters/apm
+	resourceURL := c.BaseURL + apmResource
+	log.Printf("[DEBUG] CreateApmCluster Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("POST", resourceURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] CreateApmCluster response: %v\n", resp)
+
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != 201 {
+		return nil, fmt.Errorf("apm cluster could not be created: %v", string(respBytes))
+	}
+
+	// The response body contains the secret token, so it is not logged.
+	err = json.Unmarshal(respBytes, &crudResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return crudResponse, nil
 }
 
 // CreateElasticsearchCluster creates a new elasticsearch cluster using the specified create request.
@@ -115,6 +243,38 @@ func (c *ECEClient) CreateElasticsearchCluster(createClusterRequest CreateElasti
 	return crudResponse, nil
 }
 
+// CreateElasticsearchClusterSnapshot starts a snapshot of an existing elasticsearch cluster in the specified snapshot
+// repository, using the cluster proxy. The snapshot is taken asynchronously.
+func (c *ECEClient) CreateElasticsearchClusterSnapshot(id string, repository string, snapshotName string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] CreateElasticsearchClusterSnapshot ID: %s, repository: %s, snapshot: %s\n", id, repository, snapshotName)
+
+	// PUT /api/v1/clusters/elasticsearch/{cluster_id}/proxy/_snapshot/{repository}/{snapshot}
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/proxy/_snapshot/" + repository + "/" + snapshotName
+	log.Printf("[DEBUG] CreateElasticsearchClusterSnapshot Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("PUT", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.Header.Set("X-Management-Request", "true")
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] CreateElasticsearchClusterSnapshot response: %v\n", resp)
+
+	if resp.StatusCode != 200 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster snapshot %s could not be created: %v", id, snapshotName, string(respBytes))
+	}
+
+	return resp, nil
+}
+
 // CreateKibanaCluster creates a new Kibana cluster using the specified create request.
 func (c *ECEClient) CreateKibanaCluster(createKibanaRequest CreateKibanaRequest) (crudResponse *ClusterCrudResponse, err error) {
 	log.Printf("[DEBUG] CreateKibanaCluster: %v\n", createKibanaRequest)
@@ -164,20 +324,109 @@ func (c *ECEClient) CreateKibanaCluster(createKibanaRequest CreateKibanaRequest)
 	return crudResponse, nil
 }
 
+// DeleteApmCluster deletes an existing APM cluster.
+func (c *ECEClient) DeleteApmCluster(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] DeleteApmCluster ID: %s\n", id)
+
+	// Deletion is idempotent, so a cluster that no longer exists is treated as deleted.
+	resp, err = c.GetApmCluster(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == 404 {
+		log.Printf("[DEBUG] DeleteApmCluster cluster ID not found, treating as deleted: %s\n", id)
+		return resp, nil
+	}
+
+	var clusterInfo ApmInfo
+	err = json.NewDecoder(resp.Body).Decode(&clusterInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: A cluster must be successfully _shutdown first before it can be deleted.
+	if clusterInfo.Status != "stopped" {
+		log.Printf("[DEBUG] Shutting down cluster ID: %s\n", id)
+		_, err = c.ShutdownApmCluster(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Wait for cluster shutdown.
+	log.Printf("[DEBUG] Waiting for shutdown of cluster ID: %s\n", id)
+	err = c.WaitForApmClusterStatus(id, "stopped", true)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceURL := c.BaseURL + apmResource + "/" + id
+	log.Printf("[DEBUG] DeleteApmCluster Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("DELETE", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] DeleteApmCluster response: %v\n", resp)
+
+	if resp.StatusCode == 404 {
+		log.Printf("[DEBUG] DeleteApmCluster cluster ID not found, treating as deleted: %s\n", id)
+		return resp, nil
+	}
+
+	if resp.StatusCode != 200 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: apm cluster could not be deleted: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
 // DeleteElasticsearchCluster deletes an existing elasticsearch cluster.
 func (c *ECEClient) DeleteElasticsearchCluster(id string) (resp *http.Response, err error) {
 	log.Printf("[DEBUG] DeleteElasticsearchCluster ID: %s\n", id)
 
-	// NOTE: A cluster must be successfully _shutdown first before it can be deleted.
-	log.Printf("[DEBUG] Shutting down cluster ID: %s\n", id)
-	_, err = c.ShutdownElasticsearchCluster(id)
+	// Deletion is idempotent, so a cluster that no longer exists is treated as deleted.
+	resp, err = c.GetElasticsearchCluster(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == 404 {
+		log.Printf("[DEBUG] DeleteElasticsearchCluster cluster ID not found, treating as deleted: %s\n", id)
+		return resp, nil
+	}
+
+	var clusterInfo ElasticsearchClusterInfo
+	err = json.NewDecoder(resp.Body).Decode(&clusterInfo)
 	if err != nil {
 		return nil, err
 	}
 
+	// NOTE: A cluster must be successfully _shutdown first before it can be deleted.
+	if clusterInfo.Status != "stopped" {
+		log.Printf("[DEBUG] Shutting down cluster ID: %s\n", id)
+		_, err = c.ShutdownElasticsearchCluster(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Wait for cluster shutdown.
 	log.Printf("[DEBUG] Waiting for shutdown of cluster ID: %s\n", id)
-	c.WaitForElasticsearchClusterStatus(id, "stopped", true)
+	err = c.WaitForElasticsearchClusterStatus(id, "stopped", true)
+	if err != nil {
+		return nil, err
+	}
 
 	resourceURL := c.BaseURL + elasticsearchResource + "/" + id
 	log.Printf("[DEBUG] DeleteElasticsearchCluster Resource URL: %s\n", resourceURL)
@@ -196,6 +445,11 @@ func (c *ECEClient) DeleteElasticsearchCluster(id string) (resp *http.Response,
 
 	log.Printf("[DEBUG] DeleteElasticsearchCluster response: %v\n", resp)
 
+	if resp.StatusCode == 404 {
+		log.Printf("[DEBUG] DeleteElasticsearchCluster cluster ID not found, treating as deleted: %s\n", id)
+		return resp, nil
+	}
+
 	if resp.StatusCode != 200 {
 		respBytes, _ := ioutil.ReadAll(resp.Body)
 		return nil, fmt.Errorf("%q: elasticsearch cluster could not be deleted: %v", id, string(respBytes))
@@ -208,16 +462,38 @@ func (c *ECEClient) DeleteElasticsearchCluster(id string) (resp *http.Response,
 func (c *ECEClient) DeleteKibanaCluster(id string) (resp *http.Response, err error) {
 	log.Printf("[DEBUG] DeleteKibanaCluster ID: %s\n", id)
 
-	// NOTE: A cluster must be successfully _shutdown first before it can be deleted.
-	log.Printf("[DEBUG] Shutting down cluster ID: %s\n", id)
-	_, err = c.ShutdownKibanaCluster(id)
+	// Deletion is idempotent, so a cluster that no longer exists is treated as deleted.
+	resp, err = c.GetKibanaCluster(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == 404 {
+		log.Printf("[DEBUG] DeleteKibanaCluster cluster ID not found, treating as deleted: %s\n", id)
+		return resp, nil
+	}
+
+	var clusterInfo KibanaClusterInfo
+	err = json.NewDecoder(resp.Body).Decode(&clusterInfo)
 	if err != nil {
 		return nil, err
 	}
 
+	// NOTE: A cluster must be successfully _shutdown first before it can be deleted.
+	if clusterInfo.Status != "stopped" {
+		log.Printf("[DEBUG] Shutting down cluster ID: %s\n", id)
+		_, err = c.ShutdownKibanaCluster(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Wait for cluster shutdown.
 	log.Printf("[DEBUG] Waiting for shutdown of cluster ID: %s\n", id)
-	c.WaitForKibanaClusterStatus(id, "stopped", true)
+	err = c.WaitForKibanaClusterStatus(id, "stopped", true)
+	if err != nil {
+		return nil, err
+	}
 
 	resourceURL := c.BaseURL + kibanaResource + "/" + id
 	log.Printf("[DEBUG] DeleteKibanaCluster Resource URL: %s\n", resourceURL)
@@ -236,6 +512,11 @@ func (c *ECEClient) DeleteKibanaCluster(id string) (resp *http.Response, err err
 
 	log.Printf("[DEBUG] DeleteKibanaCluster response: %v\n", resp)
 
+	if resp.StatusCode == 404 {
+		log.Printf("[DEBUG] DeleteKibanaCluster cluster ID not found, treating as deleted: %s\n", id)
+		return resp, nil
+	}
+
 	if resp.StatusCode != 200 {
 		respBytes, _ := ioutil.ReadAll(resp.Body)
 		return nil, fmt.Errorf("%q: kibana cluster could not be deleted: %v", id, string(respBytes))
@@ -244,12 +525,13 @@ func (c *ECEClient) DeleteKibanaCluster(id string) (resp *http.Response, err err
 	return resp, nil
 }
 
-// GetElasticsearchCluster returns information for an existing elasticsearch cluster.
-func (c *ECEClient) GetElasticsearchCluster(id string) (resp *http.Response, err error) {
-	log.Printf("[DEBUG] GetElasticsearchCluster ID: %s\n", id)
+// GetAllocators returns an overview of the allocators in ECE, grouped by zone.
+func (c *ECEClient) GetAllocators() (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetAllocators\n")
 
-	resourceURL := c.BaseURL + elasticsearchResource + "/" + id
-	log.Printf("[DEBUG] GetElasticsearchCluster Resource URL: %s\n", resourceURL)
+	// GET /api/v1/platform/infrastructure/allocators
+	resourceURL := c.BaseURL + allocatorsResource
+	log.Printf("[DEBUG] GetAllocators Resource URL: %s\n", resourceURL)
 	req, err := http.NewRequest("GET", resourceURL, nil)
 	if err != nil {
 		return nil, err
@@ -263,23 +545,23 @@ func (c *ECEClient) GetElasticsearchCluster(id string) (resp *http.Response, err
 		return nil, err
 	}
 
-	log.Printf("[DEBUG] GetElasticsearchCluster response: %v\n", resp)
+	log.Printf("[DEBUG] GetAllocators response: %v\n", resp)
 
-	if resp.StatusCode != 200 && resp.StatusCode != 404 {
+	// Platform endpoints return 403 for users without a platform role, which callers may treat as optional.
+	if resp.StatusCode != 200 && resp.StatusCode != 403 {
 		respBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("%q: elasticsearch cluster could not be retrieved: %v", id, string(respBytes))
+		return nil, fmt.Errorf("allocators could not be retrieved: %v", string(respBytes))
 	}
 
 	return resp, nil
 }
 
-// GetElasticsearchClusterPlan returns the plan for an existing elasticsearch cluster.
-func (c *ECEClient) GetElasticsearchClusterPlan(id string) (resp *http.Response, err error) {
-	log.Printf("[DEBUG] GetElasticsearchClusterPlan ID: %s\n", id)
+// GetApmCluster returns information for an existing APM cluster.
+func (c *ECEClient) GetApmCluster(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetApmCluster ID: %s\n", id)
 
-	// GET /api/v1/clusters/elasticsearch/{cluster_id}/plan
-	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/plan"
-	log.Printf("[DEBUG] GetElasticsearchClusterPlan Resource URL: %s\n", resourceURL)
+	resourceURL := c.BaseURL + apmResource + "/" + id
+	log.Printf("[DEBUG] GetApmCluster Resource URL: %s\n", resourceURL)
 	req, err := http.NewRequest("GET", resourceURL, nil)
 	if err != nil {
 		return nil, err
@@ -293,23 +575,23 @@ func (c *ECEClient) GetElasticsearchClusterPlan(id string) (resp *http.Response,
 		return nil, err
 	}
 
-	log.Printf("[DEBUG] GetElasticsearchClusterPlan response: %v\n", resp)
+	log.Printf("[DEBUG] GetApmCluster response: %v\n", resp)
 
 	if resp.StatusCode != 200 && resp.StatusCode != 404 {
 		respBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("%q: elasticsearch cluster plan could not be retrieved: %v", id, string(respBytes))
+		return nil, fmt.Errorf("%q: apm cluster could not be retrieved: %v", id, string(respBytes))
 	}
 
 	return resp, nil
 }
 
-// GetElasticsearchClusterPlanActivity returns the active and historical plan information for an elasticsearch cluster.
-func (c *ECEClient) GetElasticsearchClusterPlanActivity(id string) (resp *http.Response, err error) {
-	log.Printf("[DEBUG] GetElasticsearchClusterPlanActivity ID: %s\n", id)
+// GetApmClusterPlanActivity returns the active and historical plan information for an APM cluster.
+func (c *ECEClient) GetApmClusterPlanActivity(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetApmClusterPlanActivity ID: %s\n", id)
 
-	// GET /api/v1/clusters/elasticsearch/{cluster_id}/plan/activity
-	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/plan/activity"
-	log.Printf("[DEBUG] GetElasticsearchClusterPlanActivity Resource URL: %s\n", resourceURL)
+	// GET /api/v1/clusters/apm/{cluster_id}/plan/activity
+	resourceURL := c.BaseURL + apmResource + "/" + id + "/plan/activity"
+	log.Printf("[DEBUG] GetApmClusterPlanActivity Resource URL: %s\n", resourceURL)
 	req, err := http.NewRequest("GET", resourceURL, nil)
 	if err != nil {
 		return nil, err
@@ -323,22 +605,23 @@ func (c *ECEClient) GetElasticsearchClusterPlanActivity(id string) (resp *http.R
 		return nil, err
 	}
 
-	log.Printf("[DEBUG] GetElasticsearchClusterPlanActivity response: %v\n", resp)
+	log.Printf("[DEBUG] GetApmClusterPlanActivity response: %v\n", resp)
 
 	if resp.StatusCode != 200 && resp.StatusCode != 404 {
 		respBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("%q: elasticsearch cluster plan activity could not be retrieved: %v", id, string(respBytes))
+		return nil, fmt.Errorf("%q: apm cluster plan activity could not be retrieved: %v", id, string(respBytes))
 	}
 
 	return resp, nil
 }
 
-// GetKibanaCluster returns information for an existing Kibana cluster.
-func (c *ECEClient) GetKibanaCluster(id string) (resp *http.Response, err error) {
-	log.Printf("[DEBUG] GetKibanaCluster ID: %s\n", id)
+// GetDeploymentTemplate returns information for an existing deployment template.
+func (c *ECEClient) GetDeploymentTemplate(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetDeploymentTemplate ID: %s\n", id)
 
-	resourceURL := c.BaseURL + kibanaResource + "/" + id
-	log.Printf("[DEBUG] GetKibanaCluster Resource URL: %s\n", resourceURL)
+	// GET /api/v1/platform/configuration/templates/deployments/{template_id}
+	resourceURL := c.BaseURL + deploymentTemplatesResource + "/" + id
+	log.Printf("[DEBUG] GetDeploymentTemplate Resource URL: %s\n", resourceURL)
 	req, err := http.NewRequest("GET", resourceURL, nil)
 	if err != nil {
 		return nil, err
@@ -352,23 +635,22 @@ func (c *ECEClient) GetKibanaCluster(id string) (resp *http.Response, err error)
 		return nil, err
 	}
 
-	log.Printf("[DEBUG] GetKibanaCluster response: %v\n", resp)
+	log.Printf("[DEBUG] GetDeploymentTemplate response: %v\n", resp)
 
 	if resp.StatusCode != 200 && resp.StatusCode != 404 {
 		respBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("%q: kibana cluster could not be retrieved: %v", id, string(respBytes))
+		return nil, fmt.Errorf("%q: deployment template could not be retrieved: %v", id, string(respBytes))
 	}
 
 	return resp, nil
 }
 
-// GetKibanaClusterPlanActivity returns the active and historical plan information for a Kibana cluster.
-func (c *ECEClient) GetKibanaClusterPlanActivity(id string) (resp *http.Response, err error) {
-	log.Printf("[DEBUG] GetKibanaClusterPlanActivity ID: %s\n", id)
+// GetElasticsearchCluster returns information for an existing elasticsearch cluster.
+func (c *ECEClient) GetElasticsearchCluster(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetElasticsearchCluster ID: %s\n", id)
 
-	// GET /api/v1/clusters/kibana/{cluster_id}/plan/activity
-	resourceURL := c.BaseURL + kibanaResource + "/" + id + "/plan/activity"
-	log.Printf("[DEBUG] GetKibanaClusterPlanActivity Resource URL: %s\n", resourceURL)
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id
+	log.Printf("[DEBUG] GetElasticsearchCluster Resource URL: %s\n", resourceURL)
 	req, err := http.NewRequest("GET", resourceURL, nil)
 	if err != nil {
 		return nil, err
@@ -382,42 +664,514 @@ func (c *ECEClient) GetKibanaClusterPlanActivity(id string) (resp *http.Response
 		return nil, err
 	}
 
-	log.Printf("[DEBUG] GetKibanaClusterPlanActivity response: %v\n", resp)
+	log.Printf("[DEBUG] GetElasticsearchCluster response: %v\n", resp)
 
 	if resp.StatusCode != 200 && resp.StatusCode != 404 {
 		respBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("%q: kibana cluster plan activity could not be retrieved: %v", id, string(respBytes))
+		return nil, fmt.Errorf("%q: elasticsearch cluster could not be retrieved: %v", id, string(respBytes))
 	}
 
 	return resp, nil
 }
 
-// GetResponseBodyAsJSON returns a response body as a JSON document.
-func (c *ECEClient) GetResponseBodyAsJSON(resp *http.Response) (jsonResponse interface{}, err error) {
-	err = json.NewDecoder(resp.Body).Decode(&jsonResponse)
+// GetElasticsearchClusterCCSSettings returns the cross-cluster search remote clusters of an existing elasticsearch
+// cluster.
+func (c *ECEClient) GetElasticsearchClusterCCSSettings(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetElasticsearchClusterCCSSettings ID: %s\n", id)
+
+	// GET /api/v1/clusters/elasticsearch/{cluster_id}/ccs/settings
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/ccs/settings"
+	log.Printf("[DEBUG] GetElasticsearchClusterCCSSettings Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("GET", resourceURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error unmarshalling response body: %+v: %+v", err, resp.Body)
+		return nil, err
 	}
 
-	log.Printf("[DEBUG] GetResponseBodyAsJSON body: %v\n", jsonResponse)
-
-	return jsonResponse, nil
-}
-
-// GetResponseBodyAsString returns a response body as a string.
-func (c *ECEClient) GetResponseBodyAsString(resp *http.Response) (body string, err error) {
-	log.Printf("[DEBUG] GetResponseBodyAsString: %v\n", resp)
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
 
-	respBytes, err := ioutil.ReadAll(resp.Body)
+	resp, err = c.HTTPClient.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	body = string(respBytes)
+	log.Printf("[DEBUG] GetElasticsearchClusterCCSSettings response: %v\n", resp)
 
-	log.Printf("[DEBUG] GetResponseBodyAsString body: %v\n", body)
+	if resp.StatusCode != 200 && resp.StatusCode != 404 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster cross-cluster search settings could not be retrieved: %v", id, string(respBytes))
+	}
 
-	return body, nil
+	return resp, nil
+}
+
+// GetElasticsearchClusterKeystore returns the secure settings in the keystore of an existing elasticsearch cluster.
+// The values of the settings are not returned.
+func (c *ECEClient) GetElasticsearchClusterKeystore(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetElasticsearchClusterKeystore ID: %s\n", id)
+
+	// GET /api/v1/clusters/elasticsearch/{cluster_id}/keystore
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/keystore"
+	log.Printf("[DEBUG] GetElasticsearchClusterKeystore Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("GET", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] GetElasticsearchClusterKeystore response: %v\n", resp)
+
+	if resp.StatusCode != 200 && resp.StatusCode != 404 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster keystore could not be retrieved: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// GetElasticsearchClusterMetadataRaw returns the raw metadata for an existing elasticsearch cluster.
+func (c *ECEClient) GetElasticsearchClusterMetadataRaw(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetElasticsearchClusterMetadataRaw ID: %s\n", id)
+
+	// GET /api/v1/clusters/elasticsearch/{cluster_id}/metadata/raw
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/metadata/raw"
+	log.Printf("[DEBUG] GetElasticsearchClusterMetadataRaw Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("GET", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] GetElasticsearchClusterMetadataRaw response: %v\n", resp)
+
+	if resp.StatusCode != 200 && resp.StatusCode != 404 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster raw metadata could not be retrieved: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// GetElasticsearchClusterMetadataSettings returns the metadata settings for an existing elasticsearch cluster.
+func (c *ECEClient) GetElasticsearchClusterMetadataSettings(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetElasticsearchClusterMetadataSettings ID: %s\n", id)
+
+	// GET /api/v1/clusters/elasticsearch/{cluster_id}/metadata/settings
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/metadata/settings"
+	log.Printf("[DEBUG] GetElasticsearchClusterMetadataSettings Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("GET", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] GetElasticsearchClusterMetadataSettings response: %v\n", resp)
+
+	if resp.StatusCode != 200 && resp.StatusCode != 404 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster metadata settings could not be retrieved: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// GetElasticsearchClusterPlan returns the plan for an existing elasticsearch cluster.
+func (c *ECEClient) GetElasticsearchClusterPlan(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetElasticsearchClusterPlan ID: %s\n", id)
+
+	// GET /api/v1/clusters/elasticsearch/{cluster_id}/plan
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/plan"
+	log.Printf("[DEBUG] GetElasticsearchClusterPlan Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("GET", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] GetElasticsearchClusterPlan response: %v\n", resp)
+
+	if resp.StatusCode != 200 && resp.StatusCode != 404 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster plan could not be retrieved: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// GetElasticsearchClusterPlanActivity returns the active and historical plan information for an elasticsearch cluster.
+func (c *ECEClient) GetElasticsearchClusterPlanActivity(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetElasticsearchClusterPlanActivity ID: %s\n", id)
+
+	// GET /api/v1/clusters/elasticsearch/{cluster_id}/plan/activity
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/plan/activity"
+	log.Printf("[DEBUG] GetElasticsearchClusterPlanActivity Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("GET", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] GetElasticsearchClusterPlanActivity response: %v\n", resp)
+
+	if resp.StatusCode != 200 && resp.StatusCode != 404 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster plan activity could not be retrieved: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// GetElasticsearchClusterSnapshot returns the information for a snapshot of an existing elasticsearch cluster, using
+// the cluster proxy.
+func (c *ECEClient) GetElasticsearchClusterSnapshot(id string, repository string, snapshotName string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetElasticsearchClusterSnapshot ID: %s, repository: %s, snapshot: %s\n", id, repository, snapshotName)
+
+	// GET /api/v1/clusters/elasticsearch/{cluster_id}/proxy/_snapshot/{repository}/{snapshot}
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/proxy/_snapshot/" + repository + "/" + snapshotName
+	log.Printf("[DEBUG] GetElasticsearchClusterSnapshot Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("GET", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.Header.Set("X-Management-Request", "true")
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] GetElasticsearchClusterSnapshot response: %v\n", resp)
+
+	if resp.StatusCode != 200 && resp.StatusCode != 404 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster snapshot %s could not be retrieved: %v", id, snapshotName, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// GetElasticsearchClusterSnapshotRepositories returns the snapshot repositories registered in an existing
+// elasticsearch cluster, using the cluster proxy.
+func (c *ECEClient) GetElasticsearchClusterSnapshotRepositories(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetElasticsearchClusterSnapshotRepositories ID: %s\n", id)
+
+	// GET /api/v1/clusters/elasticsearch/{cluster_id}/proxy/_snapshot
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/proxy/_snapshot"
+	log.Printf("[DEBUG] GetElasticsearchClusterSnapshotRepositories Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("GET", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.Header.Set("X-Management-Request", "true")
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] GetElasticsearchClusterSnapshotRepositories response: %v\n", resp)
+
+	if resp.StatusCode != 200 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster snapshot repositories could not be retrieved: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// GetElasticsearchClusters returns the information for all elasticsearch clusters.
+func (c *ECEClient) GetElasticsearchClusters() (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetElasticsearchClusters\n")
+
+	// GET /api/v1/clusters/elasticsearch
+	resourceURL := c.BaseURL + elasticsearchResource
+	log.Printf("[DEBUG] GetElasticsearchClusters Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("GET", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] GetElasticsearchClusters response: %v\n", resp)
+
+	if resp.StatusCode != 200 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("elasticsearch clusters could not be retrieved: %v", string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// GetInstanceConfigurations returns the instance configurations that are available in ECE.
+func (c *ECEClient) GetInstanceConfigurations() (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetInstanceConfigurations\n")
+
+	// GET /api/v1/platform/configuration/instances
+	resourceURL := c.BaseURL + instanceConfigurationsResource
+	log.Printf("[DEBUG] GetInstanceConfigurations Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("GET", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] GetInstanceConfigurations response: %v\n", resp)
+
+	// Platform endpoints return 403 for users without a platform role, which callers may treat as optional.
+	if resp.StatusCode != 200 && resp.StatusCode != 403 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("instance configurations could not be retrieved: %v", string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// GetKibanaCluster returns information for an existing Kibana cluster.
+func (c *ECEClient) GetKibanaCluster(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetKibanaCluster ID: %s\n", id)
+
+	resourceURL := c.BaseURL + kibanaResource + "/" + id
+	log.Printf("[DEBUG] GetKibanaCluster Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("GET", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] GetKibanaCluster response: %v\n", resp)
+
+	if resp.StatusCode != 200 && resp.StatusCode != 404 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: kibana cluster could not be retrieved: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// GetKibanaClusterPlanActivity returns the active and historical plan information for a Kibana cluster.
+func (c *ECEClient) GetKibanaClusterPlanActivity(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetKibanaClusterPlanActivity ID: %s\n", id)
+
+	// GET /api/v1/clusters/kibana/{cluster_id}/plan/activity
+	resourceURL := c.BaseURL + kibanaResource + "/" + id + "/plan/activity"
+	log.Printf("[DEBUG] GetKibanaClusterPlanActivity Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("GET", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] GetKibanaClusterPlanActivity response: %v\n", resp)
+
+	if resp.StatusCode != 200 && resp.StatusCode != 404 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: kibana cluster plan activity could not be retrieved: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// GetStackVersions returns the Elastic Stack versions that are available in ECE.
+func (c *ECEClient) GetStackVersions() (resp *http.Response, err error) {
+	log.Printf("[DEBUG] GetStackVersions\n")
+
+	// GET /api/v1/stack/versions
+	resourceURL := c.BaseURL + stackVersionsResource
+	log.Printf("[DEBUG] GetStackVersions Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("GET", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] GetStackVersions response: %v\n", resp)
+
+	if resp.StatusCode != 200 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("stack versions could not be retrieved: %v", string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// GetResponseBodyAsJSON returns a response body as a JSON document.
+func (c *ECEClient) GetResponseBodyAsJSON(resp *http.Response) (jsonResponse interface{}, err error) {
+	err = json.NewDecoder(resp.Body).Decode(&jsonResponse)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling response body: %+v: %+v", err, resp.Body)
+	}
+
+	log.Printf("[DEBUG] GetResponseBodyAsJSON body: %v\n", jsonResponse)
+
+	return jsonResponse, nil
+}
+
+// GetResponseBodyAsString returns a response body as a string.
+func (c *ECEClient) GetResponseBodyAsString(resp *http.Response) (body string, err error) {
+	log.Printf("[DEBUG] GetResponseBodyAsString: %v\n", resp)
+
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	body = string(respBytes)
+
+	log.Printf("[DEBUG] GetResponseBodyAsString body: %v\n", body)
+
+	return body, nil
+}
+
+// UpdateApmCluster updates an existing APM cluster using the specified APM cluster plan.
+func (c *ECEClient) UpdateApmCluster(id string, apmPlan *ApmPlan) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] UpdateApmCluster: %s: %v\n", id, *apmPlan)
+
+	jsonData, err := json.Marshal(apmPlan)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonString := string(jsonData)
+	body := strings.NewReader(jsonString)
+
+	// POST /api/v1/clusters/apm/{cluster_id}/plan
+	resourceURL := c.BaseURL + apmResource + "/" + id + "/plan"
+	log.Printf("[DEBUG] UpdateApmCluster Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("POST", resourceURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] UpdateApmCluster response: %v\n", resp)
+
+	if resp.StatusCode != 202 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: apm cluster could not be updated: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// UpdateApmClusterMetadata updates the metadata for an existing APM cluster.
+func (c *ECEClient) UpdateApmClusterMetadata(id string, metadata ClusterMetadataSettings) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] UpdateApmClusterMetadata: %s: %v\n", id, metadata)
+
+	jsonData, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonString := string(jsonData)
+	body := strings.NewReader(jsonString)
+
+	// PATCH /api/v1/clusters/apm/{cluster_id}/metadata/settings
+	resourceURL := c.BaseURL + apmResource + "/" + id + "/metadata/settings"
+	log.Printf("[DEBUG] UpdateApmClusterMetadata Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("PATCH", resourceURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] UpdateApmClusterMetadata response: %v\n", resp)
+
+	if resp.StatusCode != 200 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: apm cluster metadata settings could not be updated: %v", id, string(respBytes))
+	}
+
+	return resp, nil
 }
 
 // UpdateElasticsearchCluster updates an existing elasticsearch cluster using the specified cluster plan.
@@ -432,9 +1186,87 @@ func (c *ECEClient) UpdateElasticsearchCluster(id string, clusterPlan Elasticsea
 	jsonString := string(jsonData)
 	body := strings.NewReader(jsonString)
 
-	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/plan"
-	log.Printf("[DEBUG] UpdateElasticsearchCluster Resource URL: %s\n", resourceURL)
-	req, err := http.NewRequest("POST", resourceURL, body)
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/plan"
+	log.Printf("[DEBUG] UpdateElasticsearchCluster Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("POST", resourceURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] UpdateElasticsearchCluster response: %v\n", resp)
+
+	if resp.StatusCode != 202 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster could not be updated: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// UpdateElasticsearchClusterCCSSettings replaces the cross-cluster search remote clusters of an existing
+// elasticsearch cluster.
+func (c *ECEClient) UpdateElasticsearchClusterCCSSettings(id string, settings CrossClusterSearchSettings) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] UpdateElasticsearchClusterCCSSettings: %s: %v\n", id, settings)
+
+	jsonData, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonString := string(jsonData)
+	body := strings.NewReader(jsonString)
+
+	// PUT /api/v1/clusters/elasticsearch/{cluster_id}/ccs/settings
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/ccs/settings"
+	log.Printf("[DEBUG] UpdateElasticsearchClusterCCSSettings Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("PUT", resourceURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] UpdateElasticsearchClusterCCSSettings response: %v\n", resp)
+
+	if resp.StatusCode != 200 && resp.StatusCode != 202 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster cross-cluster search settings could not be updated: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// UpdateElasticsearchClusterKeystore adds, updates, or removes secure settings in the keystore of an existing
+// elasticsearch cluster. Settings that are not included are left unchanged.
+func (c *ECEClient) UpdateElasticsearchClusterKeystore(id string, keystoreContents KeystoreContents) (resp *http.Response, err error) {
+	// Request and response bodies are not logged since they contain secure settings.
+	log.Printf("[DEBUG] UpdateElasticsearchClusterKeystore ID: %s\n", id)
+
+	jsonData, err := json.Marshal(keystoreContents)
+	if err != nil {
+		return nil, err
+	}
+
+	body := strings.NewReader(string(jsonData))
+
+	// PATCH /api/v1/clusters/elasticsearch/{cluster_id}/keystore
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/keystore"
+	log.Printf("[DEBUG] UpdateElasticsearchClusterKeystore Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("PATCH", resourceURL, body)
 	if err != nil {
 		return nil, err
 	}
@@ -447,11 +1279,11 @@ func (c *ECEClient) UpdateElasticsearchCluster(id string, clusterPlan Elasticsea
 		return nil, err
 	}
 
-	log.Printf("[DEBUG] UpdateElasticsearchCluster response: %v\n", resp)
+	log.Printf("[DEBUG] UpdateElasticsearchClusterKeystore response status: %v\n", resp.Status)
 
-	if resp.StatusCode != 202 {
+	if resp.StatusCode != 200 && resp.StatusCode != 202 {
 		respBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("%q: elasticsearch cluster could not be updated: %v", id, string(respBytes))
+		return nil, fmt.Errorf("%q: elasticsearch cluster keystore could not be updated: %v", id, string(respBytes))
 	}
 
 	return resp, nil
@@ -495,6 +1327,44 @@ func (c *ECEClient) UpdateElasticsearchClusterMetadata(id string, metadata Clust
 	return resp, nil
 }
 
+// UpdateElasticsearchClusterMetadataRaw replaces the raw metadata for an existing elasticsearch cluster.
+func (c *ECEClient) UpdateElasticsearchClusterMetadataRaw(id string, metadata map[string]interface{}) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] UpdateElasticsearchClusterMetadataRaw: %s: %v\n", id, metadata)
+
+	jsonData, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonString := string(jsonData)
+	body := strings.NewReader(jsonString)
+
+	// POST /api/v1/clusters/elasticsearch/{cluster_id}/metadata/raw
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/metadata/raw"
+	log.Printf("[DEBUG] UpdateElasticsearchClusterMetadataRaw Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("POST", resourceURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] UpdateElasticsearchClusterMetadataRaw response: %v\n", resp)
+
+	if resp.StatusCode != 200 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster raw metadata could not be updated: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
 // UpdateKibanaCluster updates an existing Kibana cluster using the specified Kibana cluster plan.
 func (c *ECEClient) UpdateKibanaCluster(id string, kibanaPlan *KibanaClusterPlan) (resp *http.Response, err error) {
 	log.Printf("[DEBUG] UpdateKibanaCluster: %s: %v\n", id, *kibanaPlan)
@@ -570,6 +1440,178 @@ func (c *ECEClient) UpdateKibanaClusterMetadata(id string, metadata ClusterMetad
 	return resp, nil
 }
 
+// ResetElasticsearchClusterPassword resets the password of the elastic superuser for an existing elasticsearch cluster.
+func (c *ECEClient) ResetElasticsearchClusterPassword(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] ResetElasticsearchClusterPassword ID: %s\n", id)
+
+	// POST /api/v1/clusters/elasticsearch/{cluster_id}/_reset-password
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/_reset-password"
+	log.Printf("[DEBUG] ResetElasticsearchClusterPassword resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("POST", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Response bodies are not logged since they contain the new password.
+	log.Printf("[DEBUG] ResetElasticsearchClusterPassword response status: %v\n", resp.Status)
+
+	if resp.StatusCode != 200 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster password could not be reset: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// RestartElasticsearchCluster restarts an existing elasticsearch cluster, starting it if it is stopped. If params is
+// nil, the ECE default restart parameters are used.
+func (c *ECEClient) RestartElasticsearchCluster(id string, params *ElasticsearchClusterRestartParams) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] RestartElasticsearchCluster ID: %s: %v\n", id, params)
+
+	// POST /api/v1/clusters/elasticsearch/{cluster_id}/_restart
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/_restart"
+	if params != nil {
+		query := url.Values{}
+		query.Set("cancel_pending", strconv.FormatBool(params.CancelPending))
+		query.Set("skip_snapshot", strconv.FormatBool(params.SkipSnapshot))
+
+		if params.GroupAttribute != "" {
+			query.Set("group_attribute", params.GroupAttribute)
+		}
+
+		if params.ShardInitWaitTime > 0 {
+			query.Set("shard_init_wait_time", strconv.Itoa(params.ShardInitWaitTime))
+		}
+
+		resourceURL += "?" + query.Encode()
+	}
+
+	log.Printf("[DEBUG] RestartElasticsearchCluster resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("POST", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] RestartElasticsearchCluster response: %v\n", resp)
+
+	if resp.StatusCode != 202 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster could not be restarted: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// RestartKibanaCluster restarts an existing kibana cluster, starting it if it is stopped.
+func (c *ECEClient) RestartKibanaCluster(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] RestartKibanaCluster ID: %s\n", id)
+
+	// POST /api/v1/clusters/kibana/{cluster_id}/_restart
+	resourceURL := c.BaseURL + kibanaResource + "/" + id + "/_restart"
+	log.Printf("[DEBUG] RestartKibanaCluster resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("POST", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] RestartKibanaCluster response: %v\n", resp)
+
+	if resp.StatusCode != 202 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: kibana cluster could not be restarted: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// SetElasticsearchClusterMonitoring sends the logs, metrics, or both of an existing elasticsearch cluster to the
+// specified monitoring cluster.
+func (c *ECEClient) SetElasticsearchClusterMonitoring(id string, destinationClusterID string, logs bool, metrics bool) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] SetElasticsearchClusterMonitoring ID: %s, destination: %s, logs: %t, metrics: %t\n", id, destinationClusterID, logs, metrics)
+
+	query := url.Values{}
+	query.Set("logs", strconv.FormatBool(logs))
+	query.Set("metrics", strconv.FormatBool(metrics))
+
+	// POST /api/v1/clusters/elasticsearch/{cluster_id}/monitoring/{dest_cluster_id}
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/monitoring/" + destinationClusterID + "?" + query.Encode()
+	log.Printf("[DEBUG] SetElasticsearchClusterMonitoring Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("POST", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SetElasticsearchClusterMonitoring response: %v\n", resp)
+
+	if resp.StatusCode != 200 && resp.StatusCode != 202 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster monitoring could not be set: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// ShutdownApmCluster shuts down an existing APM cluster.
+func (c *ECEClient) ShutdownApmCluster(id string) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] ShutdownApmCluster ID: %s\n", id)
+
+	resourceURL := c.BaseURL + apmResource + "/" + id + "/_shutdown"
+	log.Printf("[DEBUG] ShutdownApmCluster resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("POST", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] ShutdownApmCluster response: %v\n", resp)
+
+	if resp.StatusCode != 202 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: apm cluster could not be shutdown: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
 // ShutdownElasticsearchCluster shuts down an existing ECE cluster.
 func (c *ECEClient) ShutdownElasticsearchCluster(id string) (resp *http.Response, err error) {
 	log.Printf("[DEBUG] ShutdownElasticsearchCluster ID: %s\n", id)
@@ -628,6 +1670,155 @@ func (c *ECEClient) ShutdownKibanaCluster(id string) (resp *http.Response, err e
 	return resp, nil
 }
 
+// ValidateElasticsearchClusterCreate validates the specified create request without creating the elasticsearch cluster.
+func (c *ECEClient) ValidateElasticsearchClusterCreate(createClusterRequest CreateElasticsearchClusterRequest) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] ValidateElasticsearchClusterCreate: %v\n", createClusterRequest)
+
+	jsonData, err := json.Marshal(createClusterRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonString := string(jsonData)
+	log.Printf("[DEBUG] ValidateElasticsearchClusterCreate request body: %s\n", jsonString)
+
+	body := strings.NewReader(jsonString)
+
+	// POST /api/v1/clusters/elasticsearch?validate_only=true
+	resourceURL := c.BaseURL + elasticsearchResource + "?validate_only=true"
+	log.Printf("[DEBUG] ValidateElasticsearchClusterCreate Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("POST", resourceURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] ValidateElasticsearchClusterCreate response: %v\n", resp)
+
+	if resp.StatusCode != 200 && resp.StatusCode != 201 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("elasticsearch cluster create request is not valid: %v", string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// ValidateElasticsearchClusterPlan validates the specified cluster plan without applying it to the elasticsearch cluster.
+func (c *ECEClient) ValidateElasticsearchClusterPlan(id string, clusterPlan ElasticsearchClusterPlan) (resp *http.Response, err error) {
+	log.Printf("[DEBUG] ValidateElasticsearchClusterPlan: %s: %v\n", id, clusterPlan)
+
+	jsonData, err := json.Marshal(clusterPlan)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonString := string(jsonData)
+	body := strings.NewReader(jsonString)
+
+	// POST /api/v1/clusters/elasticsearch/{cluster_id}/plan?validate_only=true
+	resourceURL := c.BaseURL + elasticsearchResource + "/" + id + "/plan?validate_only=true"
+	log.Printf("[DEBUG] ValidateElasticsearchClusterPlan Resource URL: %s\n", resourceURL)
+	req, err := http.NewRequest("POST", resourceURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", jsonContentType)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err = c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] ValidateElasticsearchClusterPlan response: %v\n", resp)
+
+	if resp.StatusCode != 200 && resp.StatusCode != 202 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%q: elasticsearch cluster plan is not valid: %v", id, string(respBytes))
+	}
+
+	return resp, nil
+}
+
+// WaitForApmClusterStatus waits for an APM cluster to enter the specified status.
+func (c *ECEClient) WaitForApmClusterStatus(id string, status string, allowMissing bool) error {
+	timeoutSeconds := time.Second * time.Duration(c.Timeout)
+	log.Printf("[DEBUG] WaitForApmClusterStatus will wait for %v seconds for '%s' status for APM cluster ID: %s\n", timeoutSeconds, status, id)
+
+	return resource.Retry(timeoutSeconds, func() *resource.RetryError {
+		resp, err := c.GetApmCluster(id)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+
+		if resp.StatusCode == 404 && allowMissing {
+			return nil
+		} else if resp.StatusCode == 200 {
+			var clusterInfo ApmInfo
+			err = json.NewDecoder(resp.Body).Decode(&clusterInfo)
+			if err != nil {
+				return resource.NonRetryableError(err)
+			}
+
+			if clusterInfo.Status == status {
+				log.Printf("[DEBUG] WaitForApmClusterStatus desired APM cluster status reached: %s\n", clusterInfo.Status)
+				return nil
+			}
+
+			log.Printf("[DEBUG] WaitForApmClusterStatus current APM cluster status: %s. Desired status: %s\n", clusterInfo.Status, status)
+		}
+
+		return resource.RetryableError(
+			fmt.Errorf("%q: timeout while waiting for the APM cluster to reach %s status", id, status))
+	})
+}
+
+// WaitForElasticsearchClusterSnapshot waits for a snapshot of an elasticsearch cluster to complete, returning an error
+// if the snapshot does not succeed.
+func (c *ECEClient) WaitForElasticsearchClusterSnapshot(id string, repository string, snapshotName string) error {
+	timeoutSeconds := time.Second * time.Duration(c.Timeout)
+	log.Printf("[DEBUG] WaitForElasticsearchClusterSnapshot will wait for %v seconds for snapshot %s of cluster ID: %s\n", timeoutSeconds, snapshotName, id)
+
+	return resource.Retry(timeoutSeconds, func() *resource.RetryError {
+		resp, err := c.GetElasticsearchClusterSnapshot(id, repository, snapshotName)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+
+		if resp.StatusCode == 200 {
+			var snapshotsInfo ElasticsearchSnapshotsInfo
+			err = json.NewDecoder(resp.Body).Decode(&snapshotsInfo)
+			if err != nil {
+				return resource.NonRetryableError(err)
+			}
+
+			for _, snapshot := range snapshotsInfo.Snapshots {
+				switch snapshot.State {
+				case "SUCCESS":
+					log.Printf("[DEBUG] WaitForElasticsearchClusterSnapshot snapshot completed: %s\n", snapshotName)
+					return nil
+				case "FAILED", "PARTIAL", "INCOMPATIBLE":
+					return resource.NonRetryableError(
+						fmt.Errorf("%q: elasticsearch cluster snapshot %s did not succeed: %s %s", id, snapshotName, snapshot.State, snapshot.Reason))
+				}
+
+				log.Printf("[DEBUG] WaitForElasticsearchClusterSnapshot current snapshot state: %s\n", snapshot.State)
+			}
+		}
+
+		return resource.RetryableError(
+			fmt.Errorf("%q: timeout while waiting for elasticsearch cluster snapshot %s to complete", id, snapshotName))
+	})
+}
+
 // WaitForElasticsearchClusterStatus waits for an elasticsearch cluster to enter the specified status.
 func (c *ECEClient) WaitForElasticsearchClusterStatus(id string, status string, allowMissing bool) error {
 	timeoutSeconds := time.Second * time.Duration(c.Timeout)
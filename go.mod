@@ -3,6 +3,7 @@ module github.com/Ascendon/terraform-provider-ece
 go 1.12
 
 require (
+	github.com/hashicorp/go-version v1.1.0
 	github.com/hashicorp/terraform v0.12.0
 	github.com/mitchellh/gox v1.0.1 // indirect
 )
@@ -62,10 +62,19 @@ func Provider() terraform.ResourceProvider {
 				Default:     false,
 				Description: "Disable certificate verification of API calls.",
 			},
+			"validate_plans": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ECE_VALIDATE_PLANS", false),
+				Description: "Validate cluster plan changes with the ECE API during terraform plan, without applying them.",
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"ece_elasticsearch_cluster": resourceElasticsearchCluster(),
+			"ece_apm_cluster":                    resourceApmCluster(),
+			"ece_elasticsearch_cluster":          resourceElasticsearchCluster(),
+			"ece_elasticsearch_keystore_setting": resourceElasticsearchKeystoreSetting(),
+			"ece_kibana_cluster":                 resourceKibanaCluster(),
 		},
 
 		ConfigureFunc: providerConfigure,
@@ -84,19 +93,22 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	username := d.Get("username").(string)
 	password := d.Get("password").(string)
 	timeout := d.Get("timeout").(int)
+	validatePlans := d.Get("validate_plans").(bool)
 
 	log.Printf("[DEBUG] ECE username: %s\n", username)
 	//log.Printf("[DEBUG] ECE password: %s\n", password)
 	log.Printf("[DEBUG] ECE timeout: %v\n", timeout)
+	log.Printf("[DEBUG] ECE validate plans: %t\n", validatePlans)
 
 	httpClient := getHTTPClient(d)
 
 	eceClient := &ECEClient{
-		HTTPClient: httpClient,
-		BaseURL:    rawURL,
-		Username:   username,
-		Password:   password,
-		Timeout:    timeout,
+		HTTPClient:    httpClient,
+		BaseURL:       rawURL,
+		Username:      username,
+		Password:      password,
+		Timeout:       timeout,
+		ValidatePlans: validatePlans,
 	}
 
 	return eceClient, nil
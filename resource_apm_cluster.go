@@ -0,0 +1,540 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/structure"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func resourceApmCluster() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceApmClusterCreate,
+		Read:   resourceApmClusterRead,
+		Update: resourceApmClusterUpdate,
+		Delete: resourceApmClusterDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"elasticsearch_cluster_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The ID of the Elasticsearch cluster that the APM cluster sends data to.",
+				ForceNew:    true,
+				Required:    true,
+			},
+			"cluster_name": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The name of the APM cluster.",
+				ForceNew:    false,
+				Optional:    true,
+				Computed:    true,
+			},
+			"plan": {
+				Type:        schema.TypeList,
+				Description: "The plan for the APM cluster.",
+				ForceNew:    false,
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: apmClusterPlanSchema(),
+				},
+			},
+			"secret_token": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The secret token that APM agents use to authenticate with the APM Server. The token is returned when the APM cluster is created, so it is empty after an import unless ECE reports it in the plan system settings.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"https_endpoint": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The HTTPS URL for the APM cluster, including port.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// apmClusterPlanSchema returns the schema of an APM cluster plan.
+func apmClusterPlanSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"apm": {
+			Type:        schema.TypeList,
+			Description: "The APM Server settings.",
+			ForceNew:    false,
+			Optional:    true,
+			Computed:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: apmConfigurationSchema(true),
+			},
+		},
+		"cluster_topology": {
+			Type:        schema.TypeList,
+			Description: "The topology of the APM Server nodes, including the capacity of the nodes and where they can be allocated.",
+			Optional:    true,
+			Computed:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"apm": {
+						Type:        schema.TypeList,
+						Description: "The APM Server settings that override the plan-level APM Server settings for this topology element.",
+						ForceNew:    false,
+						Optional:    true,
+						MaxItems:    1,
+						Elem: &schema.Resource{
+							Schema: apmConfigurationSchema(false),
+						},
+					},
+					"instance_configuration_id": &schema.Schema{
+						Type:        schema.TypeString,
+						Description: "Controls the allocation of this topology element as well as allowed sizes. It needs to match the id of an existing instance configuration.",
+						ForceNew:    false,
+						Optional:    true,
+						Computed:    true,
+					},
+					"memory_per_node": &schema.Schema{
+						Type:        schema.TypeInt,
+						Description: "The memory capacity in MB for each APM Server node built in each zone. The default is 512.",
+						ForceNew:    false,
+						Optional:    true,
+						Computed:    true,
+					},
+					"zone_count": &schema.Schema{
+						Type:        schema.TypeInt,
+						Description: "The number of zones in which APM Server nodes will be placed. The default is 1.",
+						ForceNew:    false,
+						Optional:    true,
+						Computed:    true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func apmConfigurationSchema(includeVersion bool) map[string]*schema.Schema {
+	apmSchema := map[string]*schema.Schema{
+		"user_settings_json": &schema.Schema{
+			Type:             schema.TypeString,
+			Description:      "A JSON object of user settings for apm-server.yml, such as apm-server.rum settings.",
+			ForceNew:         false,
+			Optional:         true,
+			ValidateFunc:     validation.ValidateJsonString,
+			DiffSuppressFunc: structure.SuppressJsonDiff,
+		},
+		"user_settings_override_json": &schema.Schema{
+			Type:             schema.TypeString,
+			Description:      "A JSON object of administrator user settings for apm-server.yml that take precedence over user_settings_json.",
+			ForceNew:         false,
+			Optional:         true,
+			ValidateFunc:     validation.ValidateJsonString,
+			DiffSuppressFunc: structure.SuppressJsonDiff,
+		},
+		"user_settings_override_yaml": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "A YAML document of administrator user settings for apm-server.yml that take precedence over user_settings_yaml.",
+			ForceNew:    false,
+			Optional:    true,
+		},
+		"user_settings_yaml": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "A YAML document of user settings for apm-server.yml.",
+			ForceNew:    false,
+			Optional:    true,
+		},
+	}
+
+	if includeVersion {
+		apmSchema["version"] = &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The version of the APM cluster. The default is the version of the Elasticsearch cluster when the APM cluster is created.",
+			ForceNew:    false,
+			Optional:    true,
+			Computed:    true,
+		}
+	}
+
+	return apmSchema
+}
+
+func resourceApmClusterCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ECEClient)
+
+	elasticsearchClusterID := d.Get("elasticsearch_cluster_id").(string)
+	log.Printf("[DEBUG] Creating APM cluster for elasticsearch cluster ID: %s\n", elasticsearchClusterID)
+
+	apmPlan, err := expandApmClusterPlanWithVersion(client, d)
+	if err != nil {
+		return err
+	}
+
+	apmRequest := CreateApmRequest{
+		ElasticsearchClusterID: elasticsearchClusterID,
+		Name:                   d.Get("cluster_name").(string),
+		Plan:                   apmPlan,
+	}
+
+	crudResponse, err := client.CreateApmCluster(apmRequest)
+	if err != nil {
+		return err
+	}
+
+	apmClusterID := crudResponse.ApmID
+	log.Printf("[DEBUG] Created APM cluster ID: %s\n", apmClusterID)
+
+	err = client.WaitForApmClusterStatus(apmClusterID, "started", false)
+	if err != nil {
+		return err
+	}
+
+	// Confirm that the APM creation plan was successfully applied.
+	err = validateApmClusterPlanActivity(client, apmClusterID)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(apmClusterID)
+	d.Set("secret_token", crudResponse.SecretToken)
+
+	return resourceApmClusterRead(d, meta)
+}
+
+func resourceApmClusterRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ECEClient)
+
+	apmClusterID := d.Id()
+	log.Printf("[DEBUG] Reading APM cluster information for cluster ID: %s\n", apmClusterID)
+
+	resp, err := client.GetApmCluster(apmClusterID)
+	if err != nil {
+		return err
+	}
+
+	// If the resource does not exist, inform Terraform. We want to immediately
+	// return here to prevent further processing.
+	if resp.StatusCode == 404 {
+		log.Printf("[DEBUG] APM cluster ID not found: %s\n", apmClusterID)
+		d.SetId("")
+		return nil
+	}
+
+	var apmInfo ApmInfo
+	err = json.NewDecoder(resp.Body).Decode(&apmInfo)
+	if err != nil {
+		return err
+	}
+
+	d.Set("elasticsearch_cluster_id", apmInfo.ElasticsearchCluster.ElasticsearchID)
+	d.Set("cluster_name", apmInfo.Name)
+	d.Set("https_endpoint", flattenClusterEndpoint("https", apmInfo.Metadata))
+
+	// The secret token is only returned in the plan when it is part of the system settings, so keep the token
+	// from the create response otherwise.
+	currentPlan := apmInfo.PlanInfo.Current.Plan
+	if currentPlan.Apm.SystemSettings != nil && currentPlan.Apm.SystemSettings.SecretToken != "" {
+		d.Set("secret_token", currentPlan.Apm.SystemSettings.SecretToken)
+	}
+
+	plan := flattenApmClusterPlan(currentPlan)
+	log.Printf("[DEBUG] Setting APM cluster plan: %v\n", plan)
+	return d.Set("plan", plan)
+}
+
+func resourceApmClusterUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ECEClient)
+
+	apmClusterID := d.Id()
+	log.Printf("[DEBUG] Updating APM cluster ID: %s\n", apmClusterID)
+
+	d.Partial(true)
+
+	if d.HasChange("cluster_name") {
+		metadata := ClusterMetadataSettings{
+			ClusterName: d.Get("cluster_name").(string),
+		}
+
+		_, err := client.UpdateApmClusterMetadata(apmClusterID, metadata)
+		if err != nil {
+			return err
+		}
+	}
+
+	d.SetPartial("cluster_name")
+
+	if d.HasChange("plan") {
+		apmPlan, err := expandApmClusterPlanWithVersion(client, d)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.UpdateApmCluster(apmClusterID, apmPlan)
+		if err != nil {
+			return err
+		}
+
+		// Wait for the cluster plan to be initiated.
+		duration := time.Duration(5) * time.Second // 5 seconds
+		time.Sleep(duration)
+
+		err = client.WaitForApmClusterStatus(apmClusterID, "started", false)
+		if err != nil {
+			return err
+		}
+
+		// Confirm that the APM update plan was successfully applied.
+		err = validateApmClusterPlanActivity(client, apmClusterID)
+		if err != nil {
+			return err
+		}
+	}
+
+	d.Partial(false)
+
+	return resourceApmClusterRead(d, meta)
+}
+
+func resourceApmClusterDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ECEClient)
+
+	apmClusterID := d.Id()
+	log.Printf("[DEBUG] Deleting APM cluster ID: %s\n", apmClusterID)
+
+	_, err := client.DeleteApmCluster(apmClusterID)
+	if err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// expandApmClusterPlanWithVersion returns the APM cluster plan from the resource inputs. The version of the
+// Elasticsearch cluster is used when no APM version is set, and APM cannot be newer than Elasticsearch.
+// The existing secret token is kept in the plan.
+func expandApmClusterPlanWithVersion(client *ECEClient, d *schema.ResourceData) (*ApmPlan, error) {
+	apmPlan := DefaultApmPlan()
+
+	err := expandApmClusterPlan(apmPlan, d.Get("plan"))
+	if err != nil {
+		return nil, err
+	}
+
+	elasticsearchVersion, err := getElasticsearchClusterVersion(client, d.Get("elasticsearch_cluster_id").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	if apmPlan.Apm.Version == "" {
+		apmPlan.Apm.Version = elasticsearchVersion
+	}
+
+	// Send the existing secret token with plan updates, so that ECE does not generate a new token that the APM agents
+	// do not know about.
+	if secretToken := d.Get("secret_token").(string); secretToken != "" {
+		apmPlan.Apm.SystemSettings = &ApmSystemSettings{
+			SecretToken: secretToken,
+		}
+	}
+
+	err = validateVersionNotNewerThanElasticsearch("apm", apmPlan.Apm.Version, elasticsearchVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return apmPlan, nil
+}
+
+func expandApmClusterPlan(apmPlan *ApmPlan, inputPlan interface{}) error {
+	if inputPlan == nil {
+		return nil
+	}
+
+	apmPlanList := inputPlan.([]interface{})
+	if len(apmPlanList) == 0 {
+		return nil
+	}
+
+	apmPlanMap, ok := apmPlanList[0].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if v, ok := apmPlanMap["apm"]; ok {
+		err := expandApmConfiguration(&apmPlan.Apm, v.([]interface{}))
+		if err != nil {
+			return err
+		}
+	}
+
+	return expandApmClusterTopology(apmPlan, apmPlanMap)
+}
+
+func expandApmConfiguration(apmConfiguration *ApmConfiguration, apmList []interface{}) error {
+	if len(apmList) == 0 {
+		return nil
+	}
+
+	apmMap, ok := apmList[0].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if v, ok := apmMap["user_settings_json"]; ok && v.(string) != "" {
+		userSettings, err := structure.ExpandJsonFromString(v.(string))
+		if err != nil {
+			return fmt.Errorf("apm user_settings_json is not valid JSON: %v", err)
+		}
+		apmConfiguration.UserSettingsJSON = userSettings
+	}
+
+	if v, ok := apmMap["user_settings_override_json"]; ok && v.(string) != "" {
+		userSettings, err := structure.ExpandJsonFromString(v.(string))
+		if err != nil {
+			return fmt.Errorf("apm user_settings_override_json is not valid JSON: %v", err)
+		}
+		apmConfiguration.UserSettingsOverrideJSON = userSettings
+	}
+
+	if v, ok := apmMap["user_settings_override_yaml"]; ok {
+		apmConfiguration.UserSettingsOverrideYAML = v.(string)
+	}
+
+	if v, ok := apmMap["user_settings_yaml"]; ok {
+		apmConfiguration.UserSettingsYAML = v.(string)
+	}
+
+	if v, ok := apmMap["version"]; ok && v.(string) != "" {
+		apmConfiguration.Version = v.(string)
+	}
+
+	return nil
+}
+
+func expandApmClusterTopology(apmPlan *ApmPlan, apmPlanMap map[string]interface{}) error {
+	var inputClusterTopologyMap []interface{}
+
+	if v, ok := apmPlanMap["cluster_topology"]; ok {
+		inputClusterTopologyMap = v.([]interface{})
+	}
+
+	if len(inputClusterTopologyMap) == 0 {
+		return nil
+	}
+
+	clusterTopology := make([]ApmTopologyElement, 0)
+
+	for i, t := range inputClusterTopologyMap {
+		elementMap := t.(map[string]interface{})
+		clusterTopologyElement := DefaultApmTopologyElement()
+
+		if v, ok := elementMap["apm"]; ok {
+			apmList := v.([]interface{})
+			if len(apmList) > 0 {
+				apmConfiguration := &ApmConfiguration{}
+				err := expandApmConfiguration(apmConfiguration, apmList)
+				if err != nil {
+					return fmt.Errorf("cluster_topology.%d: %v", i, err)
+				}
+				clusterTopologyElement.Apm = apmConfiguration
+			}
+		}
+
+		if v, ok := elementMap["instance_configuration_id"]; ok && v.(string) != "" {
+			clusterTopologyElement.InstanceConfigurationID = v.(string)
+		}
+
+		if v, ok := elementMap["memory_per_node"]; ok && v.(int) > 0 {
+			clusterTopologyElement.Size.Value = v.(int)
+		}
+
+		if v, ok := elementMap["zone_count"]; ok && v.(int) > 0 {
+			clusterTopologyElement.ZoneCount = v.(int)
+		}
+
+		clusterTopology = append(clusterTopology, *clusterTopologyElement)
+	}
+
+	apmPlan.ClusterTopology = clusterTopology
+
+	return nil
+}
+
+func flattenApmClusterPlan(apmPlan ApmPlan) []map[string]interface{} {
+	apmPlanMaps := make([]map[string]interface{}, 1)
+
+	apmPlanMap := make(map[string]interface{})
+	apmPlanMap["apm"] = flattenApmConfiguration(apmPlan.Apm, true)
+	apmPlanMap["cluster_topology"] = flattenApmClusterTopology(apmPlan)
+
+	apmPlanMaps[0] = apmPlanMap
+
+	logJSON("Flattened APM plan", apmPlanMaps)
+
+	return apmPlanMaps
+}
+
+func flattenApmClusterTopology(apmPlan ApmPlan) []map[string]interface{} {
+	topologyMap := make([]map[string]interface{}, 0)
+
+	for _, t := range apmPlan.ClusterTopology {
+		elementMap := make(map[string]interface{})
+
+		elementMap["instance_configuration_id"] = t.InstanceConfigurationID
+		elementMap["memory_per_node"] = t.Size.Value
+		elementMap["zone_count"] = t.ZoneCount
+
+		elementMap["apm"] = make([]map[string]interface{}, 0)
+		if t.Apm != nil {
+			elementMap["apm"] = flattenApmConfiguration(*t.Apm, false)
+		}
+
+		topologyMap = append(topologyMap, elementMap)
+	}
+
+	return topologyMap
+}
+
+func flattenApmConfiguration(apmConfiguration ApmConfiguration, includeVersion bool) []map[string]interface{} {
+	apmMaps := make([]map[string]interface{}, 1)
+
+	apmMap := make(map[string]interface{})
+	apmMap["user_settings_json"] = flattenJSONObject(apmConfiguration.UserSettingsJSON)
+	apmMap["user_settings_override_json"] = flattenJSONObject(apmConfiguration.UserSettingsOverrideJSON)
+	apmMap["user_settings_override_yaml"] = apmConfiguration.UserSettingsOverrideYAML
+	apmMap["user_settings_yaml"] = apmConfiguration.UserSettingsYAML
+
+	if includeVersion {
+		apmMap["version"] = apmConfiguration.Version
+	}
+
+	apmMaps[0] = apmMap
+
+	return apmMaps
+}
+
+func validateApmClusterPlanActivity(client *ECEClient, clusterID string) error {
+	resp, err := client.GetApmClusterPlanActivity(clusterID)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == 404 {
+		return fmt.Errorf("%q: apm cluster ID was not found after update", clusterID)
+	}
+
+	var clusterPlansInfo ApmPlansInfo
+	err = json.NewDecoder(resp.Body).Decode(&clusterPlansInfo)
+	if err != nil {
+		return err
+	}
+
+	return validateClusterPlanHealth("apm", clusterID, clusterPlansInfo.Current.Healthy, clusterPlansInfo.Current.PlanAttemptLog)
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestExpandApmClusterPlan(t *testing.T) {
+	inputPlan := []interface{}{
+		map[string]interface{}{
+			"apm": []interface{}{
+				map[string]interface{}{
+					"user_settings_json":          `{"apm-server.rum.enabled": true}`,
+					"user_settings_override_json": "",
+					"user_settings_override_yaml": "",
+					"user_settings_yaml":          "apm-server.rum.enabled: true",
+					"version":                     "7.2.0",
+				},
+			},
+			"cluster_topology": []interface{}{
+				map[string]interface{}{
+					"apm": []interface{}{
+						map[string]interface{}{
+							"user_settings_override_yaml": "logging.level: debug",
+						},
+					},
+					"instance_configuration_id": "apm",
+					"memory_per_node":           1024,
+					"zone_count":                2,
+				},
+			},
+		},
+	}
+
+	apmPlan := DefaultApmPlan()
+	err := expandApmClusterPlan(apmPlan, inputPlan)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := ApmPlan{
+		Apm: ApmConfiguration{
+			UserSettingsJSON: map[string]interface{}{"apm-server.rum.enabled": true},
+			UserSettingsYAML: "apm-server.rum.enabled: true",
+			Version:          "7.2.0",
+		},
+		ClusterTopology: []ApmTopologyElement{
+			{
+				Apm: &ApmConfiguration{
+					UserSettingsOverrideYAML: "logging.level: debug",
+				},
+				InstanceConfigurationID: "apm",
+				Size: TopologySize{
+					Resource: "memory",
+					Value:    1024,
+				},
+				ZoneCount: 2,
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(*apmPlan, expected) {
+		t.Errorf("expected plan %+v, got %+v", expected, *apmPlan)
+	}
+
+	plan := flattenApmClusterPlan(*apmPlan)
+
+	if plan[0]["apm"].([]map[string]interface{})[0]["version"] != "7.2.0" {
+		t.Errorf("expected version 7.2.0, got %v", plan[0]["apm"])
+	}
+
+	if plan[0]["apm"].([]map[string]interface{})[0]["user_settings_json"] != `{"apm-server.rum.enabled":true}` {
+		t.Errorf("expected user_settings_json to be flattened, got %v", plan[0]["apm"])
+	}
+
+	topology := plan[0]["cluster_topology"].([]map[string]interface{})
+	if len(topology) != 1 {
+		t.Fatalf("expected 1 topology element, got %d", len(topology))
+	}
+
+	if topology[0]["instance_configuration_id"] != "apm" || topology[0]["memory_per_node"] != 1024 || topology[0]["zone_count"] != 2 {
+		t.Errorf("unexpected topology element: %v", topology[0])
+	}
+
+	topologyApm := topology[0]["apm"].([]map[string]interface{})[0]
+	if topologyApm["user_settings_override_yaml"] != "logging.level: debug" {
+		t.Errorf("expected the topology APM settings to be flattened, got %v", topologyApm)
+	}
+
+	if _, ok := topologyApm["version"]; ok {
+		t.Errorf("expected no version in the topology APM settings, got %v", topologyApm)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceApmCluster().Schema, map[string]interface{}{})
+	err = d.Set("plan", plan)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	roundTripPlan := DefaultApmPlan()
+	err = expandApmClusterPlan(roundTripPlan, d.Get("plan"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(*roundTripPlan, expected) {
+		t.Errorf("expected round trip plan %+v, got %+v", expected, *roundTripPlan)
+	}
+}
+
+func TestExpandApmClusterPlan_defaults(t *testing.T) {
+	apmPlan := DefaultApmPlan()
+	err := expandApmClusterPlan(apmPlan, []interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(apmPlan, DefaultApmPlan()) {
+		t.Errorf("expected the default plan, got %+v", apmPlan)
+	}
+}
+
+func TestExpandApmClusterPlanWithVersion(t *testing.T) {
+	server := testElasticsearchClusterPlanServer()
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+
+	plan := func(apmVersion string) []interface{} {
+		return []interface{}{
+			map[string]interface{}{
+				"apm": []interface{}{
+					map[string]interface{}{"version": apmVersion},
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		name                   string
+		elasticsearchClusterID string
+		plan                   []interface{}
+		expectedVersion        string
+		expectedError          string
+	}{
+		{"elasticsearch version", "1a2b3c", nil, "7.2.0", ""},
+		{"same version", "1a2b3c", plan("7.2.0"), "7.2.0", ""},
+		{"older version", "1a2b3c", plan("7.1.0"), "7.1.0", ""},
+		{"newer version", "1a2b3c", plan("7.3.0"), "", "apm version 7.3.0 cannot be newer than elasticsearch version 7.2.0"},
+		{"elasticsearch cluster not found", "4d5e6f", nil, "", "elasticsearch cluster ID was not found"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			raw := map[string]interface{}{
+				"elasticsearch_cluster_id": c.elasticsearchClusterID,
+			}
+			if c.plan != nil {
+				raw["plan"] = c.plan
+			}
+
+			d := schema.TestResourceDataRaw(t, resourceApmCluster().Schema, raw)
+
+			apmPlan, err := expandApmClusterPlanWithVersion(client, d)
+			if c.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+					t.Errorf("expected error containing %q, got %v", c.expectedError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if apmPlan.Apm.Version != c.expectedVersion {
+				t.Errorf("expected APM version %s, got %s", c.expectedVersion, apmPlan.Apm.Version)
+			}
+		})
+	}
+}
+
+func TestExpandApmClusterPlanWithVersion_secretToken(t *testing.T) {
+	server := testElasticsearchClusterPlanServer()
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+
+	d := schema.TestResourceDataRaw(t, resourceApmCluster().Schema, map[string]interface{}{
+		"elasticsearch_cluster_id": "1a2b3c",
+	})
+
+	apmPlan, err := expandApmClusterPlanWithVersion(client, d)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if apmPlan.Apm.SystemSettings != nil {
+		t.Errorf("expected no system settings before the APM cluster is created, got %+v", apmPlan.Apm.SystemSettings)
+	}
+
+	d.Set("secret_token", "s3cr3t")
+
+	apmPlan, err = expandApmClusterPlanWithVersion(client, d)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if apmPlan.Apm.SystemSettings == nil || apmPlan.Apm.SystemSettings.SecretToken != "s3cr3t" {
+		t.Errorf("expected the update plan to keep the existing secret token, got %+v", apmPlan.Apm.SystemSettings)
+	}
+}
+
+func TestValidateVersionNotNewerThanElasticsearch(t *testing.T) {
+	cases := []struct {
+		product              string
+		productVersion       string
+		elasticsearchVersion string
+		expectedError        string
+	}{
+		{"apm", "7.2.0", "7.2.0", ""},
+		{"apm", "7.1.1", "7.2.0", ""},
+		{"kibana", "6.8.0", "7.2.0", ""},
+		{"apm", "", "7.2.0", ""},
+		{"apm", "7.2.0", "", ""},
+		{"apm", "7.2.1", "7.2.0", "apm version 7.2.1 cannot be newer than elasticsearch version 7.2.0"},
+		{"kibana", "8.0.0", "7.2.0", "kibana version 8.0.0 cannot be newer than elasticsearch version 7.2.0"},
+		{"apm", "latest", "7.2.0", "apm version latest could not be parsed"},
+		{"kibana", "7.2.0", "latest", "elasticsearch version latest could not be parsed"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.product+" "+c.productVersion+" "+c.elasticsearchVersion, func(t *testing.T) {
+			err := validateVersionNotNewerThanElasticsearch(c.product, c.productVersion, c.elasticsearchVersion)
+			if c.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+				t.Errorf("expected error containing %q, got %v", c.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestValidateClusterPlanHealth(t *testing.T) {
+	err := validateClusterPlanHealth("apm", "1a2b3c", true, nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	planAttemptLog := []ClusterPlanStepInfo{
+		{
+			Status: "success",
+			InfoLog: []ClusterPlanStepLogMessageInfo{
+				{Message: "Validated plan"},
+			},
+		},
+		{
+			Status: "error",
+			InfoLog: []ClusterPlanStepLogMessageInfo{
+				{Message: "Not enough capacity"},
+			},
+		},
+	}
+
+	err = validateClusterPlanHealth("apm", "1a2b3c", false, planAttemptLog)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	if !strings.Contains(err.Error(), `"1a2b3c": apm cluster update failed`) {
+		t.Errorf("expected the APM cluster update to fail, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "Not enough capacity") || strings.Contains(err.Error(), "Validated plan") {
+		t.Errorf("expected only the failed step log messages, got %v", err)
+	}
+}
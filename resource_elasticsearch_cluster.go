@@ -5,11 +5,23 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform/helper/customdiff"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/structure"
+	"github.com/hashicorp/terraform/helper/validation"
 )
 
+// deletionProtectionMetadataKey is the raw cluster metadata key that stores whether deletion protection is enabled.
+const deletionProtectionMetadataKey = "deletion_protection"
+
 func resourceElasticsearchCluster() *schema.Resource {
 	// NOTE: Several of the aggregate schema resources below would better be mapped as TypeMap,
 	// but currently TypeMap cannot be used for non-string values due to this bug:
@@ -23,6 +35,14 @@ func resourceElasticsearchCluster() *schema.Resource {
 		Read:   resourceElasticsearchClusterRead,
 		Update: resourceElasticsearchClusterUpdate,
 		Delete: resourceElasticsearchClusterDelete,
+		CustomizeDiff: customdiff.All(
+			resourceElasticsearchClusterCustomizeDiffDesiredState,
+			resourceElasticsearchClusterCustomizeDiffVersion,
+			resourceElasticsearchClusterCustomizeDiffKibanaVersion,
+			resourceElasticsearchClusterCustomizeDiffMonitoring,
+			resourceElasticsearchClusterCustomizeDiffTopology,
+			resourceElasticsearchClusterCustomizeDiffValidateOnly,
+		),
 		Schema: map[string]*schema.Schema{
 			"cluster_name": &schema.Schema{
 				Type:        schema.TypeString,
@@ -30,6 +50,20 @@ func resourceElasticsearchCluster() *schema.Resource {
 				ForceNew:    false,
 				Required:    true,
 			},
+			"deployment_template_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The ID of an ECE deployment template whose cluster plan is used as the base for the cluster and Kibana plans.",
+				ForceNew:    false,
+				Optional:    true,
+			},
+			"desired_state": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "Whether the Elasticsearch cluster is \"started\" or \"stopped\". Plan changes cannot be applied while the cluster is stopped. The default is started.",
+				ForceNew:     false,
+				Optional:     true,
+				Default:      "started",
+				ValidateFunc: validation.StringInSlice([]string{"started", "stopped"}, false),
+			},
 			"kibana": {
 				Type:        schema.TypeList,
 				Description: "The plan for a Kibana instance that should be created as part of the Elasticsearch cluster.",
@@ -43,48 +77,32 @@ func resourceElasticsearchCluster() *schema.Resource {
 							Description: "The name of the Kibana cluster.",
 							ForceNew:    false,
 							Optional:    true,
+							Computed:    true,
+						},
+						"desired_state": &schema.Schema{
+							Type:         schema.TypeString,
+							Description:  "Whether the Kibana cluster is \"started\" or \"stopped\". Plan changes cannot be applied while the Kibana cluster is stopped. The default is started.",
+							ForceNew:     false,
+							Optional:     true,
+							Default:      "started",
+							ValidateFunc: validation.StringInSlice([]string{"started", "stopped"}, false),
+						},
+						"upgrade_with_elasticsearch": &schema.Schema{
+							Type:        schema.TypeBool,
+							Description: "Whether the Kibana version is kept in lockstep with the Elasticsearch version. Kibana is upgraded after Elasticsearch. The default is true.",
+							ForceNew:    false,
+							Optional:    true,
+							Default:     true,
 						},
 						"plan": {
 							Type:        schema.TypeList,
 							Description: "The plan for the Kibana cluster.",
 							ForceNew:    false,
 							Optional:    true,
+							Computed:    true,
 							MaxItems:    1,
 							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"cluster_topology": {
-										Type:        schema.TypeList,
-										Description: "The topology of the Kibana nodes, including the number, capacity, and type of nodes, and where they can be allocated.",
-										Optional:    true,
-										Computed:    false,
-										MaxItems:    1,
-										Elem: &schema.Resource{
-											Schema: map[string]*schema.Schema{
-												"memory_per_node": &schema.Schema{
-													Type:        schema.TypeInt,
-													Description: "The memory capacity in MB for each node of this type built in each zone. The default is 1024.",
-													ForceNew:    false,
-													Optional:    true,
-													Default:     1024,
-												},
-												"node_count_per_zone": &schema.Schema{
-													Type:        schema.TypeInt,
-													Description: "The number of nodes of this type that are allocated within each zone. The default is 1.",
-													ForceNew:    false,
-													Optional:    true,
-													Default:     1,
-												},
-												"zone_count": &schema.Schema{
-													Type:        schema.TypeInt,
-													ForceNew:    false,
-													Optional:    true,
-													Default:     1,
-													Description: "The default number of zones in which nodes will be placed. The default is 1.",
-												},
-											},
-										},
-									},
-								},
+								Schema: kibanaClusterPlanSchema(kibanaVersionSchema()),
 							},
 						},
 					},
@@ -106,26 +124,51 @@ func resourceElasticsearchCluster() *schema.Resource {
 							MinItems:    1,
 							Elem: &schema.Resource{
 								Schema: map[string]*schema.Schema{
-									"instance_configuration_id": &schema.Schema{
-										Type:        schema.TypeString,
-										Description: "Controls the allocation of this topology element as well as allowed sizes and node_types. It needs to match the id of an existing instance configuration. The default is data.default.",
+									"allocator_filter": &schema.Schema{
+										Type:             schema.TypeString,
+										Description:      "An ECE query DSL JSON document that restricts the allocators on which nodes of this type can be placed.",
+										ForceNew:         false,
+										Optional:         true,
+										ValidateFunc:     validation.ValidateJsonString,
+										DiffSuppressFunc: structure.SuppressJsonDiff,
+									},
+									"elasticsearch": {
+										Type:        schema.TypeList,
+										Description: "The Elasticsearch settings for nodes of this type, overriding the plan-level elasticsearch settings field by field.",
 										ForceNew:    false,
 										Optional:    true,
-										Default:     "data.default",
+										MaxItems:    1,
+										Elem: &schema.Resource{
+											Schema: elasticsearchConfigurationSchema(true),
+										},
+									},
+									"instance_configuration_id": &schema.Schema{
+										Type:             schema.TypeString,
+										Description:      "Controls the allocation of this topology element as well as allowed sizes and node_types. It needs to match the id of an existing instance configuration. The default is data.default, or the deployment template value when deployment_template_id is set.",
+										ForceNew:         false,
+										Optional:         true,
+										DiffSuppressFunc: suppressTopologyDefaultDiff(DefaultElasticsearchClusterTopologyElement().InstanceConfigurationID),
 									},
 									"memory_per_node": &schema.Schema{
-										Type:        schema.TypeInt,
-										Description: "The memory capacity in MB for each node of this type built in each zone. The default is 1024.",
+										Type:             schema.TypeInt,
+										Description:      "The memory capacity in MB for each node of this type built in each zone. The default is 1024, or the deployment template value when deployment_template_id is set.",
+										ForceNew:         false,
+										Optional:         true,
+										DiffSuppressFunc: suppressTopologyDefaultDiff(strconv.Itoa(DefaultElasticsearchClusterTopologyElement().MemoryPerNode)),
+									},
+									"node_attributes": &schema.Schema{
+										Type:        schema.TypeMap,
+										Description: "The node attributes for the Elasticsearch nodes of this type, such as data = \"hot\" for hot/warm architectures.",
 										ForceNew:    false,
 										Optional:    true,
-										Default:     1024,
+										Elem:        &schema.Schema{Type: schema.TypeString},
 									},
 									"node_count_per_zone": &schema.Schema{
-										Type:        schema.TypeInt,
-										Description: "The number of nodes of this type that are allocated within each zone. The default is 1.",
-										ForceNew:    false,
-										Optional:    true,
-										Default:     1,
+										Type:             schema.TypeInt,
+										Description:      "The number of nodes of this type that are allocated within each zone. The default is 1, or the deployment template value when deployment_template_id is set.",
+										ForceNew:         false,
+										Optional:         true,
+										DiffSuppressFunc: suppressTopologyDefaultDiff(strconv.Itoa(DefaultElasticsearchClusterTopologyElement().NodeCountPerZone)),
 									},
 									"node_type": {
 										Type:        schema.TypeList,
@@ -163,11 +206,11 @@ func resourceElasticsearchCluster() *schema.Resource {
 										},
 									},
 									"zone_count": &schema.Schema{
-										Type:        schema.TypeInt,
-										ForceNew:    false,
-										Optional:    true,
-										Default:     1,
-										Description: "The default number of zones in which data nodes will be placed. The default is 1.",
+										Type:             schema.TypeInt,
+										ForceNew:         false,
+										Optional:         true,
+										Description:      "The number of zones in which nodes of this type will be placed. The default is the plan zone_count, or the deployment template value when neither is set.",
+										DiffSuppressFunc: suppressTopologyZoneCountDiff,
 									},
 								},
 							},
@@ -179,37 +222,87 @@ func resourceElasticsearchCluster() *schema.Resource {
 							Required:    true,
 							MaxItems:    1,
 							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"system_settings": &schema.Schema{
-										Type:        schema.TypeList,
-										Description: "The Elasticsearch cluster system settings.",
-										ForceNew:    false,
-										Optional:    true,
-										MaxItems:    1,
-										Elem: &schema.Resource{
-											Schema: map[string]*schema.Schema{
-												"use_disk_threshold": &schema.Schema{
-													Type:        schema.TypeBool,
-													Description: "Whether to factor in the available disk space on a node before deciding whether to allocate new shards to that node or actively relocate shards away from the node.",
-													ForceNew:    false,
-													Optional:    true,
-													Default:     true,
-												},
-											},
-										},
-									},
-									"version": &schema.Schema{
-										Type:        schema.TypeString,
-										Description: "The version of the Elasticsearch cluster (must be one of the ECE supported versions).",
-										ForceNew:    false,
-										Required:    true,
-									},
-								},
+								Schema: elasticsearchConfigurationSchema(false),
 							},
 						},
+						"zone_count": &schema.Schema{
+							Type:        schema.TypeInt,
+							Description: "The default number of zones in which nodes will be placed, used by topology elements that do not set zone_count.",
+							ForceNew:    false,
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"monitoring": {
+				Type:        schema.TypeList,
+				Description: "The monitoring cluster that receives the logs and metrics of this cluster.",
+				ForceNew:    false,
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"target_cluster_id": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "The ID of the Elasticsearch cluster that receives the monitoring data.",
+							Required:    true,
+						},
+						"logs": &schema.Schema{
+							Type:        schema.TypeBool,
+							Description: "Whether the cluster logs are sent to the monitoring cluster. The default is true.",
+							Optional:    true,
+							Default:     true,
+						},
+						"metrics": &schema.Schema{
+							Type:        schema.TypeBool,
+							Description: "Whether the cluster metrics are sent to the monitoring cluster. The default is true.",
+							Optional:    true,
+							Default:     true,
+						},
+					},
+				},
+			},
+			"remote_clusters": {
+				Type:        schema.TypeList,
+				Description: "The remote clusters that this cross-cluster search cluster searches. Remote clusters are applied without a cluster plan change.",
+				ForceNew:    false,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"alias": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "The alias used to refer to the remote cluster in cross-cluster search requests.",
+							Required:    true,
+						},
+						"target_cluster_id": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "The ID of the remote Elasticsearch cluster.",
+							Required:    true,
+						},
+						"skip_unavailable": &schema.Schema{
+							Type:        schema.TypeBool,
+							Description: "Whether the remote cluster is skipped when it is unavailable during a search. The default is false.",
+							Optional:    true,
+							Default:     false,
+						},
 					},
 				},
 			},
+			"tags": &schema.Schema{
+				Type:        schema.TypeMap,
+				Description: "The tags stored in the cluster metadata, such as team, cost center, or environment.",
+				ForceNew:    false,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"metadata_raw_json": &schema.Schema{
+				Type:             schema.TypeString,
+				Description:      "A JSON object of arbitrary keys to merge into the raw cluster metadata. Only the specified keys are managed.",
+				ForceNew:         false,
+				Optional:         true,
+				ValidateFunc:     validation.ValidateJsonString,
+				DiffSuppressFunc: structure.SuppressJsonDiff,
+			},
 			"elasticsearch_username": &schema.Schema{
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -218,18 +311,441 @@ func resourceElasticsearchCluster() *schema.Resource {
 			"elasticsearch_password": &schema.Schema{
 				Type:        schema.TypeString,
 				Computed:    true,
+				Sensitive:   true,
 				Description: "The password for the created cluster.",
 			},
+			"validate_plan": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether plan changes are validated by the ECE API during terraform plan, without applying them. Plans are also validated when validate_plans is enabled for the provider.",
+				ForceNew:    false,
+				Optional:    true,
+				Default:     false,
+			},
+			"password_rotation_trigger": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "An arbitrary value that resets the password of the elastic superuser whenever it changes. Can also be used to obtain credentials for an imported cluster.",
+				ForceNew:    false,
+				Optional:    true,
+			},
+			"restart_trigger": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "An arbitrary value that performs a rolling restart of the Elasticsearch cluster whenever it changes, such as after keystore or certificate changes.",
+				ForceNew:    false,
+				Optional:    true,
+			},
+			"restart_options": {
+				Type:        schema.TypeList,
+				Description: "The options for restarts performed by restart_trigger.",
+				ForceNew:    false,
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cancel_pending": &schema.Schema{
+							Type:        schema.TypeBool,
+							Description: "Whether a pending plan is cancelled before the restart. The default is false.",
+							Optional:    true,
+							Default:     false,
+						},
+						"group_attribute": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "The node attribute used to group the nodes that are restarted together, such as __zone__ to restart a zone at a time, or __all__ to restart all nodes at once. The default is the ECE default.",
+							Optional:    true,
+						},
+						"shard_init_wait_time": &schema.Schema{
+							Type:         schema.TypeInt,
+							Description:  "The time in seconds to wait for shards to initialize after each group of nodes is restarted. The default is the ECE default.",
+							Optional:     true,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"skip_snapshot": &schema.Schema{
+							Type:        schema.TypeBool,
+							Description: "Whether the snapshot before the restart is skipped. The default is true.",
+							Optional:    true,
+							Default:     true,
+						},
+					},
+				},
+			},
+			"deletion_protection": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether the cluster is protected from deletion. Only deletion is blocked, and the value must be applied before the delete. The setting is stored in the raw cluster metadata so that other tooling can honour it. The default is false.",
+				ForceNew:    false,
+				Optional:    true,
+				Default:     false,
+			},
+			"delete_associated_clusters": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether the Kibana and APM clusters associated with the cluster that are not managed by the kibana block are deleted with the cluster. The default is false.",
+				ForceNew:    false,
+				Optional:    true,
+				Default:     false,
+			},
+			"force_destroy": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether pending plans are cancelled before the cluster is deleted, so that clusters stuck in a failed plan can be destroyed. The default is false.",
+				ForceNew:    false,
+				Optional:    true,
+				Default:     false,
+			},
+			"final_snapshot": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether a snapshot of the cluster is taken in its snapshot repository before the cluster is deleted. The default is false.",
+				ForceNew:    false,
+				Optional:    true,
+				Default:     false,
+			},
+			"final_snapshot_name_prefix": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "The prefix of the final snapshot name, which is followed by the UTC time of the snapshot. The default is final-snapshot.",
+				ForceNew:     false,
+				Optional:     true,
+				Default:      "final-snapshot",
+				ValidateFunc: validation.StringMatch(regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`), "must start with a lowercase letter or digit and contain only lowercase letters, digits, '.', '_', and '-'"),
+			},
+			"final_snapshot_ignore_failure": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether the cluster is deleted even if the final snapshot fails. The default is false.",
+				ForceNew:    false,
+				Optional:    true,
+				Default:     false,
+			},
 			"kibana_cluster_id": &schema.Schema{
 				Type:        schema.TypeString,
 				Computed:    true,
 				Description: "The ID for the created Kibana cluster.",
 			},
+			"elasticsearch_https_endpoint": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The HTTPS URL of the Elasticsearch cluster, including port.",
+			},
+			"elasticsearch_http_endpoint": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The HTTP URL of the Elasticsearch cluster, including port.",
+			},
+			"kibana_https_endpoint": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The HTTPS URL of the Kibana cluster, including port.",
+			},
+			"cloud_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The cloud ID of the Elasticsearch cluster, which can be used to configure Beats and Logstash.",
+			},
+			"healthy": &schema.Schema{
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the Elasticsearch cluster is healthy.",
+			},
+			"status": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The status of the Elasticsearch cluster, such as started or stopped.",
+			},
+			"instances": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The instances of the Elasticsearch cluster and where they are located.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"instance_name": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the instance.",
+						},
+						"zone": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The availability zone in which the instance is located.",
+						},
+						"allocator_id": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The ID of the allocator on which the instance is running.",
+						},
+						"healthy": &schema.Schema{
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the instance is healthy.",
+						},
+						"memory_capacity": &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The memory capacity of the instance in MB.",
+						},
+						"disk_space_available": &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The disk capacity of the instance in MB.",
+						},
+						"disk_space_used": &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The disk usage of the instance in MB.",
+						},
+						"service_roles": &schema.Schema{
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The roles of the instance, such as master, data, ingest, or ml.",
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"maintenance_mode": &schema.Schema{
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the instance is in maintenance mode.",
+						},
+					},
+				},
+			},
+			"ports": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The ports on which the Elasticsearch cluster is available.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"http": &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The HTTP port.",
+						},
+						"https": &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The HTTPS port.",
+						},
+					},
+				},
+			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: resourceElasticsearchClusterImport,
+		},
+	}
+}
+
+// elasticsearchConfigurationSchema returns the schema of the Elasticsearch settings. The settings of a topology
+// element only include the user settings, which default to the plan-level values.
+func elasticsearchConfigurationSchema(isTopologyElement bool) map[string]*schema.Schema {
+	elasticsearchSchema := map[string]*schema.Schema{
+		"user_settings_json": &schema.Schema{
+			Type:         schema.TypeString,
+			Description:  "A JSON object of user settings for elasticsearch.yml.",
+			ForceNew:     false,
+			Optional:     true,
+			ValidateFunc: validation.ValidateJsonString,
+		},
+		"user_settings_override_json": &schema.Schema{
+			Type:         schema.TypeString,
+			Description:  "A JSON object of administrator user settings for elasticsearch.yml that take precedence over user_settings_json.",
+			ForceNew:     false,
+			Optional:     true,
+			ValidateFunc: validation.ValidateJsonString,
+		},
+		"user_settings_override_yaml": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "A YAML document of administrator user settings for elasticsearch.yml that take precedence over user_settings_yaml.",
+			ForceNew:    false,
+			Optional:    true,
+		},
+		"user_settings_yaml": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "A YAML document of user settings for elasticsearch.yml.",
+			ForceNew:    false,
+			Optional:    true,
 		},
 	}
+
+	if isTopologyElement {
+		for _, v := range elasticsearchSchema {
+			v.DiffSuppressFunc = suppressTopologyElasticsearchDiff
+		}
+
+		return elasticsearchSchema
+	}
+
+	for k, v := range elasticsearchSchema {
+		if strings.HasSuffix(k, "_json") {
+			v.DiffSuppressFunc = structure.SuppressJsonDiff
+		}
+	}
+
+	elasticsearchSchema["system_settings"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Description: "The Elasticsearch cluster system settings.",
+		ForceNew:    false,
+		Optional:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"use_disk_threshold": &schema.Schema{
+					Type:        schema.TypeBool,
+					Description: "Whether to factor in the available disk space on a node before deciding whether to allocate new shards to that node or actively relocate shards away from the node.",
+					ForceNew:    false,
+					Optional:    true,
+					Default:     true,
+				},
+			},
+		},
+	}
+
+	elasticsearchSchema["version"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "The version of the Elasticsearch cluster (must be one of the ECE supported versions).",
+		ForceNew:    false,
+		Required:    true,
+	}
+
+	return elasticsearchSchema
+}
+
+// kibanaClusterPlanSchema returns the schema of a Kibana cluster plan, using the specified schema for the Kibana
+// version.
+func kibanaClusterPlanSchema(versionSchema *schema.Schema) map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"kibana": {
+			Type:        schema.TypeList,
+			Description: "The Kibana cluster settings.",
+			ForceNew:    false,
+			Optional:    true,
+			Computed:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: kibanaPlanConfigurationSchema(versionSchema),
+			},
+		},
+		"cluster_topology": {
+			Type:        schema.TypeList,
+			Description: "The topology of the Kibana nodes, including the number, capacity, and type of nodes, and where they can be allocated.",
+			Optional:    true,
+			Computed:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"instance_configuration_id": &schema.Schema{
+						Type:        schema.TypeString,
+						Description: "Controls the allocation of this topology element as well as allowed sizes. It needs to match the id of an existing instance configuration.",
+						ForceNew:    false,
+						Optional:    true,
+						Computed:    true,
+					},
+					"kibana": {
+						Type:        schema.TypeList,
+						Description: "The Kibana settings that override the plan-level Kibana settings for this topology element.",
+						ForceNew:    false,
+						Optional:    true,
+						MaxItems:    1,
+						Elem: &schema.Resource{
+							Schema: kibanaConfigurationSchema(),
+						},
+					},
+					"memory_per_node": &schema.Schema{
+						Type:             schema.TypeInt,
+						Description:      "The memory capacity in MB for each node of this type built in each zone. The default is 1024, or the deployment template value when deployment_template_id is set.",
+						ForceNew:         false,
+						Optional:         true,
+						DiffSuppressFunc: suppressTopologyDefaultDiff(strconv.Itoa(DefaultKibanaClusterTopologyElement().MemoryPerNode)),
+					},
+					"node_count_per_zone": &schema.Schema{
+						Type:             schema.TypeInt,
+						Description:      "The number of nodes of this type that are allocated within each zone. The default is 1, or the deployment template value when deployment_template_id is set.",
+						ForceNew:         false,
+						Optional:         true,
+						DiffSuppressFunc: suppressTopologyDefaultDiff(strconv.Itoa(DefaultKibanaClusterTopologyElement().NodeCountPerZone)),
+					},
+					"zone_count": &schema.Schema{
+						Type:             schema.TypeInt,
+						ForceNew:         false,
+						Optional:         true,
+						DiffSuppressFunc: suppressTopologyDefaultDiff(strconv.Itoa(DefaultKibanaClusterTopologyElement().ZoneCount)),
+						Description:      "The default number of zones in which nodes will be placed. The default is 1, or the deployment template value when deployment_template_id is set.",
+					},
+				},
+			},
+		},
+	}
+}
+
+// kibanaConfigurationSchema returns the schema for Kibana settings, which are used both at the plan level and
+// as topology element overrides. The version can only be specified at the plan level.
+func kibanaConfigurationSchema() map[string]*schema.Schema {
+	kibanaSchema := map[string]*schema.Schema{
+		"system_settings": &schema.Schema{
+			Type:        schema.TypeList,
+			Description: "The Kibana system settings.",
+			ForceNew:    false,
+			Optional:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"elasticsearch_url": &schema.Schema{
+						Type:        schema.TypeString,
+						Description: "The URL of the Elasticsearch cluster used by Kibana, if not the associated cluster.",
+						ForceNew:    false,
+						Optional:    true,
+					},
+					"elasticsearch_username": &schema.Schema{
+						Type:        schema.TypeString,
+						Description: "The username Kibana uses to connect to the Elasticsearch cluster.",
+						ForceNew:    false,
+						Optional:    true,
+					},
+				},
+			},
+		},
+		"user_settings_json": &schema.Schema{
+			Type:             schema.TypeString,
+			Description:      "A JSON object of user settings for kibana.yml, such as server.basePath or xpack.reporting settings.",
+			ForceNew:         false,
+			Optional:         true,
+			ValidateFunc:     validation.ValidateJsonString,
+			DiffSuppressFunc: structure.SuppressJsonDiff,
+		},
+		"user_settings_override_json": &schema.Schema{
+			Type:             schema.TypeString,
+			Description:      "A JSON object of administrator user settings for kibana.yml that take precedence over user_settings_json.",
+			ForceNew:         false,
+			Optional:         true,
+			ValidateFunc:     validation.ValidateJsonString,
+			DiffSuppressFunc: structure.SuppressJsonDiff,
+		},
+		"user_settings_override_yaml": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "A YAML document of administrator user settings for kibana.yml that take precedence over user_settings_yaml.",
+			ForceNew:    false,
+			Optional:    true,
+		},
+		"user_settings_yaml": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "A YAML document of user settings for kibana.yml, such as SAML settings.",
+			ForceNew:    false,
+			Optional:    true,
+		},
+	}
+
+	return kibanaSchema
+}
+
+// kibanaPlanConfigurationSchema returns the schema of the plan-level Kibana settings, which include the version.
+func kibanaPlanConfigurationSchema(versionSchema *schema.Schema) map[string]*schema.Schema {
+	kibanaSchema := kibanaConfigurationSchema()
+	kibanaSchema["version"] = versionSchema
+
+	return kibanaSchema
+}
+
+// kibanaVersionSchema returns the schema of the version of a Kibana cluster managed by an Elasticsearch cluster.
+func kibanaVersionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:             schema.TypeString,
+		Description:      "The version of the Kibana cluster. Ignored unless upgrade_with_elasticsearch is false, in which case the Elasticsearch version is not applied to Kibana.",
+		ForceNew:         false,
+		Optional:         true,
+		Computed:         true,
+		DiffSuppressFunc: suppressKibanaVersionDiff,
+	}
 }
 
 func resourceElasticsearchClusterCreate(d *schema.ResourceData, meta interface{}) error {
@@ -282,22 +798,77 @@ func resourceElasticsearchClusterCreate(d *schema.ResourceData, meta interface{}
 	d.Set("elasticsearch_username", crudResponse.Credentials.Username)
 	d.Set("elasticsearch_password", crudResponse.Credentials.Password)
 
-	// Wait for the Kibana cluster to be created if it was included in the creation request.
-	kibanaClusterID := crudResponse.KibanaClusterID
-	if kibanaClusterID != "" {
-		err = client.WaitForKibanaClusterStatus(kibanaClusterID, "started", false)
+	// Tags and raw metadata are not part of the create request, so apply them to the new cluster.
+	if _, ok := d.GetOk("tags"); ok {
+		metadata := ClusterMetadataSettings{
+			ClusterName: clusterName,
+			Tags:        expandClusterMetadataTags(d),
+		}
+
+		_, err = client.UpdateElasticsearchClusterMetadata(elasticsearchClusterID, metadata)
 		if err != nil {
 			return err
 		}
+	}
 
-		// Confirm that the Kibana creation plan was successfully applied.
-		err = validateKibanaClusterPlanActivity(client, kibanaClusterID)
+	if _, ok := d.GetOk("metadata_raw_json"); ok {
+		err = updateElasticsearchClusterMetadataRaw(client, elasticsearchClusterID, d)
 		if err != nil {
 			return err
 		}
+	}
 
-		log.Printf("[DEBUG] Created Kibana cluster ID: %s\n", kibanaClusterID)
-		d.Set("kibana_cluster_id", kibanaClusterID)
+	if d.Get("deletion_protection").(bool) {
+		err = updateElasticsearchClusterDeletionProtection(client, elasticsearchClusterID, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	if remoteClusters := d.Get("remote_clusters").([]interface{}); len(remoteClusters) > 0 {
+		err = updateElasticsearchClusterRemoteClusters(client, elasticsearchClusterID, d)
+		if err != nil {
+			return err
+		}
+	}
+
+	if monitoring := d.Get("monitoring").([]interface{}); len(monitoring) > 0 {
+		err = updateElasticsearchClusterMonitoring(client, elasticsearchClusterID, d)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Wait for the Kibana cluster to be created if it was included in the creation request.
+	kibanaClusterID := crudResponse.KibanaClusterID
+	if kibanaClusterID != "" {
+		err = client.WaitForKibanaClusterStatus(kibanaClusterID, "started", false)
+		if err != nil {
+			return err
+		}
+
+		// Confirm that the Kibana creation plan was successfully applied.
+		err = validateKibanaClusterPlanActivity(client, kibanaClusterID)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("[DEBUG] Created Kibana cluster ID: %s\n", kibanaClusterID)
+		d.Set("kibana_cluster_id", kibanaClusterID)
+
+		if d.Get("kibana.0.desired_state").(string) == "stopped" {
+			err = setKibanaClusterState(client, kibanaClusterID, "stopped")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if d.Get("desired_state").(string) == "stopped" {
+		err = setElasticsearchClusterState(client, elasticsearchClusterID, "stopped")
+		if err != nil {
+			return err
+		}
 	}
 
 	return resourceElasticsearchClusterRead(d, meta)
@@ -338,23 +909,52 @@ func resourceElasticsearchClusterRead(d *schema.ResourceData, meta interface{})
 	log.Printf("[DEBUG] Setting elasticsearch cluster_name: %v\n", clusterInfo.ClusterName)
 	d.Set("cluster_name", clusterInfo.ClusterName)
 
-	plan := flattenElasticsearchClusterPlan(clusterInfo)
+	log.Printf("[DEBUG] Setting elasticsearch cluster endpoints: %v\n", clusterInfo.Metadata)
+	d.Set("elasticsearch_https_endpoint", flattenClusterEndpoint("https", clusterInfo.Metadata))
+	d.Set("elasticsearch_http_endpoint", flattenClusterEndpoint("http", clusterInfo.Metadata))
+	d.Set("cloud_id", clusterInfo.Metadata.CloudID)
+	d.Set("ports", flattenClusterMetadataPorts(clusterInfo.Metadata.Ports))
+
+	log.Printf("[DEBUG] Setting elasticsearch cluster status: %v, healthy: %t\n", clusterInfo.Status, clusterInfo.Healthy)
+	d.Set("healthy", clusterInfo.Healthy)
+	d.Set("status", clusterInfo.Status)
+	d.Set("desired_state", flattenDesiredState(clusterInfo.Status))
+	d.Set("instances", flattenClusterInstances(clusterInfo.Topology.Instances))
+
+	plan := flattenElasticsearchClusterPlan(clusterInfo, d.Get("plan.0.zone_count").(int))
+	err = flattenElasticsearchTemplateUserSettings(d, meta, plan[0]["elasticsearch"].([]map[string]interface{}))
+	if err != nil {
+		return err
+	}
+
 	log.Printf("[DEBUG] Setting elasticsearch cluster plan: %v\n", plan)
 	d.Set("plan", plan)
 	if err != nil {
 		return err
 	}
 
-	if clusterInfo.AssociatedKibanaClusters != nil && len(clusterInfo.AssociatedKibanaClusters) > 0 {
-		kibanaClusterID := clusterInfo.AssociatedKibanaClusters[0].KibanaID
-		log.Printf("[DEBUG] Setting Kibana cluster ID: %v\n", kibanaClusterID)
-		d.Set("kibana_cluster_id", kibanaClusterID)
+	// Only the Kibana cluster that this resource created or imported is read. Other Kibana clusters associated with the
+	// Elasticsearch cluster, such as those managed by ece_kibana_cluster resources, are not adopted.
+	if kibanaClusterID := d.Get("kibana_cluster_id").(string); kibanaClusterID != "" {
+		err = readKibanaCluster(client, kibanaClusterID, d)
 		if err != nil {
 			return err
 		}
+	} else {
+		d.Set("kibana_https_endpoint", "")
 	}
 
-	return nil
+	err = readElasticsearchClusterMetadata(client, clusterID, d)
+	if err != nil {
+		return err
+	}
+
+	monitoring := flattenElasticsearchClusterMonitoring(clusterInfo.ElasticsearchMonitoringInfo, d.Get("monitoring").([]interface{}))
+	log.Printf("[DEBUG] Setting elasticsearch cluster monitoring: %v\n", monitoring)
+	d.Set("monitoring", monitoring)
+
+	// Remote clusters that are added outside of Terraform are also read, so that they are detected as drift.
+	return readElasticsearchClusterRemoteClusters(client, clusterID, d)
 }
 
 func resourceElasticsearchClusterUpdate(d *schema.ResourceData, meta interface{}) error {
@@ -374,11 +974,15 @@ func resourceElasticsearchClusterUpdate(d *schema.ResourceData, meta interface{}
 		return fmt.Errorf("%q: cluster ID was not found for update", clusterID)
 	}
 
-	if d.HasChange("cluster_name") {
+	if d.HasChange("cluster_name") || d.HasChange("tags") {
 		metadata := ClusterMetadataSettings{
 			ClusterName: d.Get("cluster_name").(string),
 		}
 
+		if d.HasChange("tags") {
+			metadata.Tags = expandClusterMetadataTags(d)
+		}
+
 		_, err = client.UpdateElasticsearchClusterMetadata(clusterID, metadata)
 		if err != nil {
 			return err
@@ -386,6 +990,58 @@ func resourceElasticsearchClusterUpdate(d *schema.ResourceData, meta interface{}
 	}
 
 	d.SetPartial("cluster_name")
+	d.SetPartial("tags")
+
+	if d.HasChange("metadata_raw_json") {
+		err = updateElasticsearchClusterMetadataRaw(client, clusterID, d)
+		if err != nil {
+			return err
+		}
+	}
+
+	d.SetPartial("metadata_raw_json")
+
+	if d.HasChange("deletion_protection") {
+		err = updateElasticsearchClusterDeletionProtection(client, clusterID, d.Get("deletion_protection").(bool))
+		if err != nil {
+			return err
+		}
+	}
+
+	d.SetPartial("deletion_protection")
+
+	if d.HasChange("password_rotation_trigger") {
+		log.Printf("[DEBUG] Resetting elastic superuser password for cluster ID: %s\n", clusterID)
+		resp, err := client.ResetElasticsearchClusterPassword(clusterID)
+		if err != nil {
+			return err
+		}
+
+		var credentials ClusterCredentials
+		err = json.NewDecoder(resp.Body).Decode(&credentials)
+		if err != nil {
+			return err
+		}
+
+		if credentials.Username != "" {
+			d.Set("elasticsearch_username", credentials.Username)
+		}
+		d.Set("elasticsearch_password", credentials.Password)
+	}
+
+	d.SetPartial("password_rotation_trigger")
+	d.SetPartial("elasticsearch_username")
+	d.SetPartial("elasticsearch_password")
+
+	// A stopped cluster is started before any plan changes are applied, and a cluster is stopped only after they have
+	// been applied.
+	desiredState := d.Get("desired_state").(string)
+	if d.HasChange("desired_state") && desiredState == "started" {
+		err = setElasticsearchClusterState(client, clusterID, desiredState)
+		if err != nil {
+			return err
+		}
+	}
 
 	if d.HasChange("plan") {
 		clusterPlan, err := expandElasticsearchClusterPlan(d, meta)
@@ -416,13 +1072,75 @@ func resourceElasticsearchClusterUpdate(d *schema.ResourceData, meta interface{}
 
 	d.SetPartial("plan")
 
-	if d.HasChange("kibana") {
+	if d.HasChange("remote_clusters") {
+		err = updateElasticsearchClusterRemoteClusters(client, clusterID, d)
+		if err != nil {
+			return err
+		}
+	}
+
+	d.SetPartial("remote_clusters")
+
+	if d.HasChange("monitoring") {
+		err = updateElasticsearchClusterMonitoring(client, clusterID, d)
+		if err != nil {
+			return err
+		}
+	}
+
+	d.SetPartial("monitoring")
+
+	// Starting a stopped cluster already restarts it, so the restart trigger only applies to a running cluster.
+	if d.HasChange("restart_trigger") && d.Get("restart_trigger").(string) != "" {
+		if desiredState == "started" && !d.HasChange("desired_state") {
+			err = restartElasticsearchCluster(client, clusterID, d)
+			if err != nil {
+				return err
+			}
+		} else {
+			log.Printf("[DEBUG] Skipping restart of elasticsearch cluster ID: %s, desired state: %s\n", clusterID, desiredState)
+		}
+	}
+
+	d.SetPartial("restart_trigger")
+	d.SetPartial("restart_options")
+
+	kibanaDesiredState := d.Get("kibana.0.desired_state").(string)
+	kibanaStateChange := d.HasChange("kibana.0.desired_state") && d.Get("kibana_cluster_id").(string) != ""
+	if kibanaStateChange && kibanaDesiredState == "started" {
+		err = setKibanaClusterState(client, d.Get("kibana_cluster_id").(string), kibanaDesiredState)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Kibana is updated after the Elasticsearch plan so that Kibana is never upgraded ahead of Elasticsearch.
+	kibanaVersionChange := kibanaVersionFollowsElasticsearch(d) && d.HasChange("plan.0.elasticsearch.0.version")
+	if kibanaClusterChanged(d) || kibanaVersionChange {
 		err = updateKibanaCluster(client, clusterID, d, meta)
 		if err != nil {
 			return err
 		}
 	}
 
+	// A new Kibana cluster is started when it is created, so it is stopped here if required.
+	if kibanaClusterID := d.Get("kibana_cluster_id").(string); kibanaClusterID != "" && kibanaDesiredState == "stopped" &&
+		d.HasChange("kibana.0.desired_state") {
+		err = setKibanaClusterState(client, kibanaClusterID, kibanaDesiredState)
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("desired_state") && desiredState == "stopped" {
+		err = setElasticsearchClusterState(client, clusterID, desiredState)
+		if err != nil {
+			return err
+		}
+	}
+
+	d.SetPartial("desired_state")
+
 	d.Partial(false)
 
 	return resourceElasticsearchClusterRead(d, meta)
@@ -432,375 +1150,1951 @@ func resourceElasticsearchClusterDelete(d *schema.ResourceData, meta interface{}
 	client := meta.(*ECEClient)
 	clusterID := d.Id()
 
-	log.Printf("[DEBUG] Deleting cluster ID: %s\n", clusterID)
-	_, err := client.DeleteElasticsearchCluster(clusterID)
+	// Deletion is idempotent, so a cluster that no longer exists is treated as deleted.
+	resp, err := client.GetElasticsearchCluster(clusterID)
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func expandElasticsearchClusterPlan(d *schema.ResourceData, meta interface{}) (clusterPlan *ElasticsearchClusterPlan, err error) {
-	clusterPlanList := d.Get("plan").([]interface{})
-	clusterPlanMap := clusterPlanList[0].(map[string]interface{})
+	if resp.StatusCode == 404 {
+		log.Printf("[DEBUG] Elasticsearch cluster ID not found, treating as deleted: %s\n", clusterID)
+		return nil
+	}
 
-	clusterTopology := expandElasticsearchClusterTopology(clusterPlanMap)
-	elasticsearchConfiguration, err := expandElasticsearchConfiguration(clusterPlanMap)
+	var clusterInfo ElasticsearchClusterInfo
+	err = json.NewDecoder(resp.Body).Decode(&clusterInfo)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	clusterPlan = &ElasticsearchClusterPlan{
-		Elasticsearch:   *elasticsearchConfiguration,
-		ClusterTopology: clusterTopology,
+	// Deletion protection is read from the state, since there is no configuration when the resource is destroyed.
+	if d.Get("deletion_protection").(bool) {
+		return fmt.Errorf("%q: the cluster cannot be deleted because deletion_protection is enabled, set deletion_protection to false and apply the change first", clusterID)
 	}
 
-	return clusterPlan, nil
-}
+	if d.Get("final_snapshot").(bool) {
+		// The snapshot requests are proxied to the cluster, so no snapshot can be taken while it is stopped.
+		if clusterInfo.Status == "stopped" {
+			err = fmt.Errorf("the cluster is stopped, set desired_state to started and apply the change first")
+		} else {
+			err = takeElasticsearchClusterFinalSnapshot(client, clusterID, d.Get("final_snapshot_name_prefix").(string))
+		}
 
-func expandElasticsearchClusterTopology(clusterPlanMap map[string]interface{}) []ElasticsearchClusterTopologyElement {
-	inputClusterTopologyMap := clusterPlanMap["cluster_topology"].([]interface{})
-	clusterTopology := make([]ElasticsearchClusterTopologyElement, 0)
+		if err != nil {
+			if !d.Get("final_snapshot_ignore_failure").(bool) {
+				return fmt.Errorf("%q: the cluster was not deleted because the final snapshot failed, set final_snapshot_ignore_failure to delete the cluster without a snapshot: %v", clusterID, err)
+			}
 
-	for _, t := range inputClusterTopologyMap {
-		elementMap := t.(map[string]interface{})
-		clusterTopologyElement := DefaultElasticsearchClusterTopologyElement()
+			log.Printf("[WARN] Final snapshot failed, deleting cluster ID: %s anyway: %v\n", clusterID, err)
+		}
+	}
 
-		if v, ok := elementMap["instance_configuration_id"]; ok {
-			clusterTopologyElement.InstanceConfigurationID = v.(string)
+	if d.Get("force_destroy").(bool) {
+		log.Printf("[DEBUG] Cancelling pending plan before deleting cluster ID: %s\n", clusterID)
+		_, err := client.CancelElasticsearchClusterPendingPlan(clusterID)
+		if err != nil {
+			return err
 		}
+	}
 
-		if v, ok := elementMap["memory_per_node"]; ok {
-			clusterTopologyElement.MemoryPerNode = v.(int)
+	// Associated clusters are deleted first so that they are not orphaned in ECE.
+	err = deleteElasticsearchClusterAssociations(client, clusterInfo, d.Get("kibana_cluster_id").(string), d.Get("delete_associated_clusters").(bool))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Deleting cluster ID: %s\n", clusterID)
+	_, err = client.DeleteElasticsearchCluster(clusterID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// deleteElasticsearchClusterAssociations deletes the Kibana cluster that is managed by the kibana block of an
+// Elasticsearch cluster and, if deleteAssociatedClusters is set, the other Kibana and APM clusters that are associated
+// with it. Each deletion waits for the associated cluster to shut down. All associated clusters are attempted, and an
+// error identifying the clusters that were and were not deleted is returned if any deletion fails.
+func deleteElasticsearchClusterAssociations(client *ECEClient, clusterInfo ElasticsearchClusterInfo, kibanaClusterID string, deleteAssociatedClusters bool) error {
+	var deleted, failed []string
+
+	for _, kibana := range clusterInfo.AssociatedKibanaClusters {
+		if kibana.KibanaID != kibanaClusterID && !deleteAssociatedClusters {
+			log.Printf("[DEBUG] Keeping Kibana cluster ID: %s associated with cluster ID: %s\n", kibana.KibanaID, clusterInfo.ClusterID)
+			continue
 		}
 
-		if v, ok := elementMap["node_count_per_zone"]; ok {
-			clusterTopologyElement.NodeCountPerZone = v.(int)
+		log.Printf("[DEBUG] Deleting Kibana cluster ID: %s associated with cluster ID: %s\n", kibana.KibanaID, clusterInfo.ClusterID)
+		_, err := client.DeleteKibanaCluster(kibana.KibanaID)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("Kibana cluster %s: %v", kibana.KibanaID, err))
+		} else {
+			deleted = append(deleted, "Kibana cluster "+kibana.KibanaID)
 		}
+	}
 
-		if v, ok := elementMap["node_type"]; ok {
-			nodeType := DefaultElasticsearchNodeType()
-			nodeTypeMaps := v.([]interface{})
-			if len(nodeTypeMaps) > 0 {
-				expandElasticsearchNodeTypeFromMap(nodeType, nodeTypeMaps[0].(map[string]interface{}))
-			}
-			clusterTopologyElement.NodeType = *nodeType
+	for _, apm := range clusterInfo.AssociatedApmClusters {
+		if !deleteAssociatedClusters {
+			log.Printf("[DEBUG] Keeping APM cluster ID: %s associated with cluster ID: %s\n", apm.ApmID, clusterInfo.ClusterID)
+			continue
 		}
 
-		if v, ok := elementMap["zone_count"]; ok {
-			clusterTopologyElement.ZoneCount = v.(int)
+		log.Printf("[DEBUG] Deleting APM cluster ID: %s associated with cluster ID: %s\n", apm.ApmID, clusterInfo.ClusterID)
+		_, err := client.DeleteApmCluster(apm.ApmID)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("APM cluster %s: %v", apm.ApmID, err))
+		} else {
+			deleted = append(deleted, "APM cluster "+apm.ApmID)
 		}
+	}
 
-		clusterTopology = append(clusterTopology, *clusterTopologyElement)
+	if len(failed) == 0 {
+		return nil
 	}
 
-	// Create a default cluster topology element if none is provided in the input map.
-	if len(clusterTopology) == 0 {
-		clusterTopology = append(clusterTopology, *DefaultElasticsearchClusterTopologyElement())
+	deletedMessage := "none"
+	if len(deleted) > 0 {
+		deletedMessage = strings.Join(deleted, ", ")
 	}
 
-	return clusterTopology
+	return fmt.Errorf("%q: the elasticsearch cluster was not deleted because associated clusters could not be deleted.\n\nFailed:\n%s\n\nDeleted: %s",
+		clusterInfo.ClusterID, strings.Join(failed, "\n"), deletedMessage)
 }
 
-func expandElasticsearchConfiguration(clusterPlanMap map[string]interface{}) (elasticsearchConfiguration *ElasticsearchConfiguration, err error) {
-	// Get the single elasticsearch element from the plan element.
-	elasticsearchList := clusterPlanMap["elasticsearch"].([]interface{})
+// resourceElasticsearchClusterImport imports an Elasticsearch cluster by cluster ID or cluster name, optionally
+// followed by the ID of the associated Kibana cluster in the form <cluster_id>:<kibana_id>. The Kibana cluster, or the
+// first associated Kibana cluster if no Kibana ID is specified, is imported into the kibana block.
+func resourceElasticsearchClusterImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(*ECEClient)
 
-	if len(elasticsearchList) < 1 {
-		return nil, fmt.Errorf("cluster version is required")
+	clusterRef, kibanaClusterID := parseElasticsearchClusterImportID(d.Id())
+	log.Printf("[DEBUG] Importing elasticsearch cluster: %s\n", clusterRef)
+
+	clusterInfo, err := findElasticsearchCluster(client, clusterRef)
+	if err != nil {
+		return nil, err
 	}
 
-	elasticsearchMap, ok := elasticsearchList[0].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("cluster version is required")
+	d.SetId(clusterInfo.ClusterID)
+
+	// Attributes that only exist in the configuration are set to their schema defaults.
+	err = setSchemaDefaults(d, resourceElasticsearchCluster().Schema)
+	if err != nil {
+		return nil, err
 	}
 
-	elasticsearchConfiguration = &ElasticsearchConfiguration{
-		Version: elasticsearchMap["version"].(string),
+	kibanaClusterID, err = importedKibanaClusterID(clusterInfo, kibanaClusterID)
+	if err != nil {
+		return nil, err
+	} else if kibanaClusterID == "" {
+		return []*schema.ResourceData{d}, nil
 	}
 
-	systemSettings := DefaultElasticsearchSystemSettings()
+	log.Printf("[DEBUG] Importing Kibana cluster: %s\n", kibanaClusterID)
 
-	if v, ok := elasticsearchMap["system_settings"]; ok {
-		err := expandElasticsearchSystemSettings(systemSettings, v.(interface{}))
-		if err != nil {
-			return nil, err
-		}
+	resp, err := client.GetKibanaCluster(kibanaClusterID)
+	if err != nil {
+		return nil, err
 	}
 
-	elasticsearchConfiguration.SystemSettings = *systemSettings
+	if resp.StatusCode == 404 {
+		return nil, fmt.Errorf("%q: Kibana cluster not found", kibanaClusterID)
+	}
 
-	return elasticsearchConfiguration, nil
-}
+	var kibanaInfo KibanaClusterInfo
+	err = json.NewDecoder(resp.Body).Decode(&kibanaInfo)
+	if err != nil {
+		return nil, err
+	}
 
-func expandElasticsearchSystemSettings(systemSettings *ElasticsearchSystemSettings, inputSystemSettings interface{}) error {
-	if inputSystemSettings == nil {
-		return nil
+	// The Kibana version follows the Elasticsearch version unless the imported versions differ.
+	kibanaVersion := kibanaInfo.PlanInfo.Current.Plan.Kibana.Version
+	elasticsearchVersion := clusterInfo.PlanInfo.Current.Plan.Elasticsearch.Version
+	upgradeWithElasticsearch := kibanaVersion == "" || kibanaVersion == elasticsearchVersion
+
+	d.Set("kibana_cluster_id", kibanaClusterID)
+	err = d.Set("kibana", flattenKibanaCluster(kibanaInfo, upgradeWithElasticsearch))
+	if err != nil {
+		return nil, err
 	}
 
-	systemSettingsList := inputSystemSettings.([]interface{})
-	if len(systemSettingsList) == 0 {
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceElasticsearchClusterCustomizeDiffDesiredState confirms at plan time that no plan changes are made to an
+// Elasticsearch or Kibana cluster that remains stopped, since ECE cannot apply plans to stopped clusters.
+func resourceElasticsearchClusterCustomizeDiffDesiredState(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
 		return nil
 	}
 
-	systemSettingsMap, ok := systemSettingsList[0].(map[string]interface{})
-	if !ok {
-		return nil
+	oldState, newState := d.GetChange("desired_state")
+	if oldState.(string) == "stopped" && newState.(string) == "stopped" && (d.HasChange("plan") || d.HasChange("remote_clusters") || d.HasChange("monitoring")) {
+		return fmt.Errorf("plan, remote cluster, and monitoring changes cannot be applied while the elasticsearch cluster is stopped, set desired_state to \"started\"")
 	}
 
-	if v, ok := systemSettingsMap["use_disk_threshold"]; ok {
-		systemSettings.UseDiskThreshold = v.(bool)
+	oldState, newState = d.GetChange("kibana.0.desired_state")
+	if oldState.(string) == "stopped" && newState.(string) == "stopped" {
+		kibanaVersionChange := kibanaVersionFollowsElasticsearch(d) && d.HasChange("plan.0.elasticsearch.0.version")
+		if d.HasChange("kibana.0.plan") || kibanaVersionChange {
+			return fmt.Errorf("plan changes cannot be applied while the Kibana cluster is stopped, set kibana.0.desired_state to \"started\"")
+		}
 	}
 
 	return nil
 }
 
-func expandKibanaClusterPlan(kibanaPlan *KibanaClusterPlan, inputPlan interface{}) error {
-	if inputPlan == nil {
+// resourceElasticsearchClusterCustomizeDiffVersion confirms at plan time that a new or changed Elasticsearch
+// version is available in ECE and, for existing clusters, that the upgrade path is supported.
+func resourceElasticsearchClusterCustomizeDiffVersion(d *schema.ResourceDiff, meta interface{}) error {
+	versionKey := "plan.0.elasticsearch.0.version"
+	if !d.HasChange(versionKey) || !d.NewValueKnown(versionKey) {
 		return nil
 	}
 
-	kibanaPlanList := inputPlan.([]interface{})
-	if len(kibanaPlanList) == 0 {
+	o, n := d.GetChange(versionKey)
+	currentVersion := o.(string)
+	targetVersion := n.(string)
+	if targetVersion == "" {
 		return nil
 	}
 
-	kibanaPlanMap, ok := kibanaPlanList[0].(map[string]interface{})
-	if !ok {
-		return nil
+	client := meta.(*ECEClient)
+	resp, err := client.GetStackVersions()
+	if err != nil {
+		return err
 	}
 
-	if v, ok := kibanaPlanMap["zone_count"]; ok {
-		kibanaPlan.ZoneCount = v.(int)
+	var stackVersions StackVersionConfigs
+	err = json.NewDecoder(resp.Body).Decode(&stackVersions)
+	if err != nil {
+		return err
 	}
 
-	expandKibanaClusterTopology(kibanaPlan, kibanaPlanMap)
+	// Only existing clusters have an upgrade path to validate.
+	if d.Id() == "" {
+		currentVersion = ""
+	}
 
-	return nil
+	return validateElasticsearchVersionChange(stackVersions.Stacks, currentVersion, targetVersion)
 }
 
-func expandKibanaClusterTopology(kibanaPlan *KibanaClusterPlan, kibanaPlanMap map[string]interface{}) {
-	var inputClusterTopologyMap []interface{}
-
-	if v, ok := kibanaPlanMap["cluster_topology"]; ok {
-		inputClusterTopologyMap = v.([]interface{})
+// resourceElasticsearchClusterCustomizeDiffKibanaVersion confirms at plan time that a Kibana version that does not
+// follow the Elasticsearch version is not newer than the Elasticsearch version. The Elasticsearch plan is applied
+// before the Kibana plan, so the check cannot wait until the Kibana plan is applied.
+func resourceElasticsearchClusterCustomizeDiffKibanaVersion(d *schema.ResourceDiff, meta interface{}) error {
+	kibanaVersionKey := "kibana.0.plan.0.kibana.0.version"
+	elasticsearchVersionKey := "plan.0.elasticsearch.0.version"
+	if kibanaVersionFollowsElasticsearch(d) || !d.NewValueKnown(kibanaVersionKey) || !d.NewValueKnown(elasticsearchVersionKey) {
+		return nil
 	}
 
-	if inputClusterTopologyMap == nil {
-		return
+	return validateVersionNotNewerThanElasticsearch("kibana", d.Get(kibanaVersionKey).(string), d.Get(elasticsearchVersionKey).(string))
+}
+
+// resourceElasticsearchClusterCustomizeDiffMonitoring confirms at plan time that a monitoring block sends logs,
+// metrics, or both to the monitoring cluster.
+func resourceElasticsearchClusterCustomizeDiffMonitoring(d *schema.ResourceDiff, meta interface{}) error {
+	if len(d.Get("monitoring").([]interface{})) == 0 || !d.NewValueKnown("monitoring.0.logs") || !d.NewValueKnown("monitoring.0.metrics") {
+		return nil
 	}
 
-	clusterTopology := make([]KibanaClusterTopologyElement, 0)
+	if !d.Get("monitoring.0.logs").(bool) && !d.Get("monitoring.0.metrics").(bool) {
+		return fmt.Errorf("at least one of monitoring.0.logs or monitoring.0.metrics must be true")
+	}
 
-	for _, t := range inputClusterTopologyMap {
-		elementMap := t.(map[string]interface{})
-		clusterTopologyElement := DefaultKibanaClusterTopologyElement()
+	return nil
+}
 
-		if v, ok := elementMap["memory_per_node"]; ok {
+// elasticsearchClusterTopologyKnown returns true when all the cluster topology values of the plan are known.
+func elasticsearchClusterTopologyKnown(d *schema.ResourceDiff) bool {
+	keys := []string{"plan.0.cluster_topology.#", "plan.0.zone_count"}
+
+	topologyKeys := []string{
+		"instance_configuration_id",
+		"memory_per_node",
+		"node_count_per_zone",
+		"zone_count",
+		"node_type.0.data",
+		"node_type.0.ingest",
+		"node_type.0.master",
+		"node_type.0.ml",
+	}
+	for i := range d.Get("plan.0.cluster_topology").([]interface{}) {
+		for _, k := range topologyKeys {
+			keys = append(keys, fmt.Sprintf("plan.0.cluster_topology.%d.%s", i, k))
+		}
+	}
+
+	for _, k := range keys {
+		if !d.NewValueKnown(k) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// resourceElasticsearchClusterCustomizeDiffTopology validates the expanded cluster plan at plan time, both locally
+// and against the instance configurations and zones available in ECE.
+func resourceElasticsearchClusterCustomizeDiffTopology(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() != "" && !d.HasChange("plan") {
+		return nil
+	}
+
+	// Unknown topology values expand to zero and would disable their topology elements, so they are only
+	// validated at apply time.
+	if !elasticsearchClusterTopologyKnown(d) {
+		return nil
+	}
+
+	clusterPlan, err := expandElasticsearchClusterPlan(d, meta)
+	if err != nil {
+		return err
+	}
+
+	return validateElasticsearchClusterPlanTopology(meta.(*ECEClient), clusterPlan)
+}
+
+// validateElasticsearchClusterPlanTopology validates the topology of a cluster plan against the instance
+// configurations and zones available in ECE.
+func validateElasticsearchClusterPlanTopology(client *ECEClient, clusterPlan *ElasticsearchClusterPlan) error {
+	// The instance configurations and allocators are only checked if the user is allowed to read them.
+	resp, err := client.GetInstanceConfigurations()
+	if err != nil {
+		return err
+	}
+
+	var instanceConfigurations []InstanceConfiguration
+	if resp.StatusCode == 403 {
+		log.Printf("[WARN] Instance configurations cannot be read. Instance configurations are not validated.\n")
+	} else {
+		err = json.NewDecoder(resp.Body).Decode(&instanceConfigurations)
+		if err != nil {
+			return err
+		}
+	}
+
+	resp, err = client.GetAllocators()
+	if err != nil {
+		return err
+	}
+
+	var allocatorOverview AllocatorOverview
+	if resp.StatusCode == 403 {
+		log.Printf("[WARN] Allocators cannot be read. Zone counts are not validated.\n")
+	} else {
+		err = json.NewDecoder(resp.Body).Decode(&allocatorOverview)
+		if err != nil {
+			return err
+		}
+	}
+
+	return validateElasticsearchClusterTopology(clusterPlan.ClusterTopology, instanceConfigurations, len(allocatorOverview.Zones))
+}
+
+// resourceElasticsearchClusterCustomizeDiffValidateOnly sends new or changed cluster plans to ECE with validate_only
+// set, if enabled, so that plans rejected by the API are reported during terraform plan.
+func resourceElasticsearchClusterCustomizeDiffValidateOnly(d *schema.ResourceDiff, meta interface{}) error {
+	client := meta.(*ECEClient)
+	if !client.ValidatePlans && !d.Get("validate_plan").(bool) {
+		return nil
+	}
+
+	if d.Id() != "" && !d.HasChange("plan") {
+		return nil
+	}
+
+	clusterPlan, err := expandElasticsearchClusterPlan(d, meta)
+	if err != nil {
+		return err
+	}
+
+	if d.Id() != "" {
+		log.Printf("[DEBUG] Validating elasticsearch cluster plan for cluster ID: %s\n", d.Id())
+		_, err = client.ValidateElasticsearchClusterPlan(d.Id(), *clusterPlan)
+		return err
+	}
+
+	createClusterRequest := CreateElasticsearchClusterRequest{
+		ClusterName: d.Get("cluster_name").(string),
+		Plan:        *clusterPlan,
+	}
+
+	kibanaRequest, err := expandKibanaCreateRequest(d, meta)
+	if err != nil {
+		return err
+	} else if kibanaRequest != nil {
+		createClusterRequest.Kibana = &CreateKibanaInCreateElasticsearchRequest{
+			ClusterName: kibanaRequest.ClusterName,
+			Plan:        kibanaRequest.Plan,
+		}
+	}
+
+	log.Printf("[DEBUG] Validating elasticsearch cluster create request for cluster name: %s\n", createClusterRequest.ClusterName)
+	_, err = client.ValidateElasticsearchClusterCreate(createClusterRequest)
+	return err
+}
+
+func expandClusterMetadataTags(d *schema.ResourceData) *[]MetadataItem {
+	tags := make([]MetadataItem, 0)
+
+	for k, v := range d.Get("tags").(map[string]interface{}) {
+		tags = append(tags, MetadataItem{
+			Key:   k,
+			Value: v.(string),
+		})
+	}
+
+	return &tags
+}
+
+func expandElasticsearchClusterPlan(d resourceDataGetter, meta interface{}) (clusterPlan *ElasticsearchClusterPlan, err error) {
+	clusterPlanList := d.Get("plan").([]interface{})
+	clusterPlanMap := clusterPlanList[0].(map[string]interface{})
+
+	// Use the topology and Elasticsearch settings from the deployment template, if any, as the base for the cluster
+	// plan.
+	var baseTopology []ElasticsearchClusterTopologyElement
+	var baseElasticsearch *ElasticsearchConfiguration
+	deploymentTemplate, err := getDeploymentTemplate(d, meta)
+	if err != nil {
+		return nil, err
+	} else if deploymentTemplate != nil {
+		baseTopology = deploymentTemplate.ClusterTemplate.Plan.ClusterTopology
+		baseElasticsearch = &deploymentTemplate.ClusterTemplate.Plan.Elasticsearch
+
+		// See note in flattenElasticsearchClusterTopology about the deprecated plan zone count.
+		for i := range baseTopology {
+			if baseTopology[i].ZoneCount == 0 {
+				baseTopology[i].ZoneCount = deploymentTemplate.ClusterTemplate.Plan.ZoneCount
+			}
+		}
+	}
+
+	clusterTopology, err := expandElasticsearchClusterTopology(clusterPlanMap, baseTopology)
+	if err != nil {
+		return nil, err
+	}
+
+	elasticsearchConfiguration, err := expandElasticsearchConfiguration(clusterPlanMap, baseElasticsearch)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterPlan = &ElasticsearchClusterPlan{
+		Elasticsearch:   *elasticsearchConfiguration,
+		ClusterTopology: clusterTopology,
+	}
+
+	if v, ok := clusterPlanMap["zone_count"]; ok {
+		clusterPlan.ZoneCount = v.(int)
+	}
+
+	return clusterPlan, nil
+}
+
+func expandElasticsearchClusterTopology(clusterPlanMap map[string]interface{}, baseTopology []ElasticsearchClusterTopologyElement) ([]ElasticsearchClusterTopologyElement, error) {
+	inputClusterTopologyMap := clusterPlanMap["cluster_topology"].([]interface{})
+	clusterTopology := make([]ElasticsearchClusterTopologyElement, 0)
+
+	for i, t := range inputClusterTopologyMap {
+		elementMap := t.(map[string]interface{})
+
+		var instanceConfigurationID string
+		if v, ok := elementMap["instance_configuration_id"]; ok {
+			instanceConfigurationID = v.(string)
+		}
+
+		clusterTopologyElement := baseElasticsearchClusterTopologyElement(baseTopology, i, instanceConfigurationID)
+
+		if v, ok := elementMap["elasticsearch"]; ok {
+			elasticsearchConfiguration, err := expandElasticsearchTopologyConfiguration(v.([]interface{}))
+			if err != nil {
+				return nil, fmt.Errorf("cluster_topology.%d.elasticsearch: %v", i, err)
+			}
+
+			if elasticsearchConfiguration != nil {
+				clusterTopologyElement.Elasticsearch = elasticsearchConfiguration
+			}
+		}
+
+		if v, ok := elementMap["allocator_filter"]; ok && v.(string) != "" {
+			allocatorFilter, err := structure.ExpandJsonFromString(v.(string))
+			if err != nil {
+				return nil, fmt.Errorf("cluster_topology.%d.allocator_filter is not valid JSON: %v", i, err)
+			}
+			clusterTopologyElement.AllocatorFilter = allocatorFilter
+		}
+
+		if instanceConfigurationID != "" {
+			clusterTopologyElement.InstanceConfigurationID = instanceConfigurationID
+		}
+
+		if v, ok := elementMap["memory_per_node"]; ok && v.(int) > 0 {
 			clusterTopologyElement.MemoryPerNode = v.(int)
 		}
 
-		if v, ok := elementMap["node_count_per_zone"]; ok {
+		if v, ok := elementMap["node_attributes"]; ok {
+			nodeAttributes := make(map[string]string)
+			for k, a := range v.(map[string]interface{}) {
+				nodeAttributes[k] = a.(string)
+			}
+
+			if len(nodeAttributes) > 0 {
+				clusterTopologyElement.NodeAttributes = nodeAttributes
+			}
+		}
+
+		if v, ok := elementMap["node_count_per_zone"]; ok && v.(int) > 0 {
 			clusterTopologyElement.NodeCountPerZone = v.(int)
 		}
 
-		if v, ok := elementMap["zone_count"]; ok {
+		// The node type of the base topology element is kept unless a node_type is specified.
+		if v, ok := elementMap["node_type"]; ok {
+			nodeTypeMaps := v.([]interface{})
+			if len(nodeTypeMaps) > 0 && nodeTypeMaps[0] != nil {
+				nodeType := DefaultElasticsearchNodeType()
+				expandElasticsearchNodeTypeFromMap(nodeType, nodeTypeMaps[0].(map[string]interface{}))
+				clusterTopologyElement.NodeType = *nodeType
+			}
+		}
+
+		// The plan zone count is the default for topology elements that do not set a zone count.
+		if v, ok := elementMap["zone_count"]; ok && v.(int) > 0 {
+			clusterTopologyElement.ZoneCount = v.(int)
+		} else if v, ok := clusterPlanMap["zone_count"]; ok && v.(int) > 0 {
 			clusterTopologyElement.ZoneCount = v.(int)
 		}
 
 		clusterTopology = append(clusterTopology, *clusterTopologyElement)
 	}
 
-	// Create a default cluster topology element if none is provided in the input map.
+	// Use the base topology, or create a default cluster topology element, if none is provided in the input map.
 	if len(clusterTopology) == 0 {
-		clusterTopology = append(clusterTopology, *DefaultKibanaClusterTopologyElement())
+		if len(baseTopology) > 0 {
+			clusterTopology = append(clusterTopology, baseTopology...)
+		} else {
+			clusterTopology = append(clusterTopology, *DefaultElasticsearchClusterTopologyElement())
+		}
 	}
 
-	kibanaPlan.ClusterTopology = clusterTopology
+	return clusterTopology, nil
+}
+
+// baseElasticsearchClusterTopologyElement returns the element to use as the base for the input topology element at
+// the specified index. A base topology element with a matching instance configuration ID is preferred, followed by the
+// base topology element at the same index. Values that are not set in the base element use the provider defaults.
+func baseElasticsearchClusterTopologyElement(baseTopology []ElasticsearchClusterTopologyElement, index int, instanceConfigurationID string) *ElasticsearchClusterTopologyElement {
+	defaultElement := DefaultElasticsearchClusterTopologyElement()
+
+	var baseElement *ElasticsearchClusterTopologyElement
+	if instanceConfigurationID != "" {
+		for i := range baseTopology {
+			if baseTopology[i].InstanceConfigurationID == instanceConfigurationID {
+				baseElement = &baseTopology[i]
+				break
+			}
+		}
+	} else if index < len(baseTopology) {
+		baseElement = &baseTopology[index]
+	}
+
+	if baseElement == nil {
+		return defaultElement
+	}
+
+	clusterTopologyElement := *baseElement
+
+	if clusterTopologyElement.InstanceConfigurationID == "" {
+		clusterTopologyElement.InstanceConfigurationID = defaultElement.InstanceConfigurationID
+	}
 
-	return
+	if clusterTopologyElement.MemoryPerNode == 0 {
+		clusterTopologyElement.MemoryPerNode = defaultElement.MemoryPerNode
+	}
+
+	if clusterTopologyElement.NodeCountPerZone == 0 {
+		clusterTopologyElement.NodeCountPerZone = defaultElement.NodeCountPerZone
+	}
+
+	if clusterTopologyElement.ZoneCount == 0 {
+		clusterTopologyElement.ZoneCount = defaultElement.ZoneCount
+	}
+
+	return &clusterTopologyElement
 }
 
-func expandKibanaCreateRequest(d *schema.ResourceData, meta interface{}) (kibanaRequest *CreateKibanaRequest, err error) {
-	kibanaList := d.Get("kibana").([]interface{})
+func expandElasticsearchClusterRestartParams(d *schema.ResourceData) *ElasticsearchClusterRestartParams {
+	params := &ElasticsearchClusterRestartParams{
+		SkipSnapshot: true,
+	}
 
-	if kibanaList == nil || len(kibanaList) == 0 {
-		log.Printf("[DEBUG] Kibana configuration not specified. No Kibana instance will be created.\n")
+	restartOptionsList := d.Get("restart_options").([]interface{})
+	if len(restartOptionsList) == 0 || restartOptionsList[0] == nil {
+		return params
+	}
+
+	restartOptionsMap := restartOptionsList[0].(map[string]interface{})
+	params.CancelPending = restartOptionsMap["cancel_pending"].(bool)
+	params.GroupAttribute = restartOptionsMap["group_attribute"].(string)
+	params.ShardInitWaitTime = restartOptionsMap["shard_init_wait_time"].(int)
+	params.SkipSnapshot = restartOptionsMap["skip_snapshot"].(bool)
+
+	return params
+}
+
+// expandElasticsearchClusterMonitoring returns the monitoring cluster ID and whether logs and metrics are sent to it,
+// or an empty cluster ID if monitoring is not configured.
+func expandElasticsearchClusterMonitoring(monitoringList []interface{}) (targetClusterID string, logs bool, metrics bool) {
+	if len(monitoringList) < 1 || monitoringList[0] == nil {
+		return "", false, false
+	}
+
+	monitoringMap := monitoringList[0].(map[string]interface{})
+	return monitoringMap["target_cluster_id"].(string), monitoringMap["logs"].(bool), monitoringMap["metrics"].(bool)
+}
+
+func expandElasticsearchClusterRemoteClusters(remoteClustersList []interface{}) []RemoteClusterInfo {
+	remoteClusters := make([]RemoteClusterInfo, 0, len(remoteClustersList))
+
+	for _, r := range remoteClustersList {
+		remoteClusterMap := r.(map[string]interface{})
+		remoteClusters = append(remoteClusters, RemoteClusterInfo{
+			Alias:           remoteClusterMap["alias"].(string),
+			ElasticsearchID: remoteClusterMap["target_cluster_id"].(string),
+			SkipUnavailable: remoteClusterMap["skip_unavailable"].(bool),
+		})
+	}
+
+	return remoteClusters
+}
+
+// expandElasticsearchConfiguration returns the plan-level Elasticsearch settings from the resource inputs. Settings
+// that are not set in the resource inputs are taken from baseConfiguration, if any.
+func expandElasticsearchConfiguration(clusterPlanMap map[string]interface{}, baseConfiguration *ElasticsearchConfiguration) (elasticsearchConfiguration *ElasticsearchConfiguration, err error) {
+	// Get the single elasticsearch element from the plan element.
+	elasticsearchList := clusterPlanMap["elasticsearch"].([]interface{})
+
+	if len(elasticsearchList) < 1 {
+		return nil, fmt.Errorf("cluster version is required")
+	}
+
+	elasticsearchMap, ok := elasticsearchList[0].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cluster version is required")
+	}
+
+	elasticsearchConfiguration = &ElasticsearchConfiguration{}
+	systemSettings := DefaultElasticsearchSystemSettings()
+
+	if baseConfiguration != nil {
+		*elasticsearchConfiguration = *baseConfiguration
+
+		if baseConfiguration.SystemSettings != nil {
+			*systemSettings = *baseConfiguration.SystemSettings
+		}
+	}
+
+	elasticsearchConfiguration.Version = elasticsearchMap["version"].(string)
+
+	if v, ok := elasticsearchMap["system_settings"]; ok {
+		err := expandElasticsearchSystemSettings(systemSettings, v.(interface{}))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	elasticsearchConfiguration.SystemSettings = systemSettings
+
+	err = expandElasticsearchUserSettings(elasticsearchConfiguration, elasticsearchMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return elasticsearchConfiguration, nil
+}
+
+// expandElasticsearchTopologyConfiguration expands the Elasticsearch settings of a topology element, returning nil if
+// no settings override the plan-level values.
+func expandElasticsearchTopologyConfiguration(elasticsearchList []interface{}) (*ElasticsearchConfiguration, error) {
+	if len(elasticsearchList) == 0 {
 		return nil, nil
 	}
 
-	kibanaPlan := DefaultKibanaClusterPlan()
+	elasticsearchMap, ok := elasticsearchList[0].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
 
-	if kibanaList[0] == nil {
-		log.Printf("[DEBUG] Empty Kibana configuration specified. A default Kibana instance will be created.\n")
+	elasticsearchConfiguration := &ElasticsearchConfiguration{}
+
+	err := expandElasticsearchUserSettings(elasticsearchConfiguration, elasticsearchMap)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(elasticsearchConfiguration.UserSettingsJSON) == 0 && len(elasticsearchConfiguration.UserSettingsOverrideJSON) == 0 &&
+		elasticsearchConfiguration.UserSettingsOverrideYAML == "" && elasticsearchConfiguration.UserSettingsYAML == "" {
+		return nil, nil
+	}
 
-		kibanaRequest = &CreateKibanaRequest{
-			Plan: kibanaPlan,
+	return elasticsearchConfiguration, nil
+}
+
+func expandElasticsearchUserSettings(elasticsearchConfiguration *ElasticsearchConfiguration, elasticsearchMap map[string]interface{}) error {
+	if v, ok := elasticsearchMap["user_settings_json"]; ok && v.(string) != "" {
+		userSettings, err := structure.ExpandJsonFromString(v.(string))
+		if err != nil {
+			return fmt.Errorf("elasticsearch user_settings_json is not valid JSON: %v", err)
+		}
+		elasticsearchConfiguration.UserSettingsJSON = userSettings
+	}
+
+	if v, ok := elasticsearchMap["user_settings_override_json"]; ok && v.(string) != "" {
+		userSettings, err := structure.ExpandJsonFromString(v.(string))
+		if err != nil {
+			return fmt.Errorf("elasticsearch user_settings_override_json is not valid JSON: %v", err)
 		}
+		elasticsearchConfiguration.UserSettingsOverrideJSON = userSettings
+	}
+
+	if v, ok := elasticsearchMap["user_settings_override_yaml"]; ok && v.(string) != "" {
+		elasticsearchConfiguration.UserSettingsOverrideYAML = v.(string)
+	}
+
+	if v, ok := elasticsearchMap["user_settings_yaml"]; ok && v.(string) != "" {
+		elasticsearchConfiguration.UserSettingsYAML = v.(string)
+	}
+
+	return nil
+}
+
+func expandElasticsearchSystemSettings(systemSettings *ElasticsearchSystemSettings, inputSystemSettings interface{}) error {
+	if inputSystemSettings == nil {
+		return nil
+	}
+
+	systemSettingsList := inputSystemSettings.([]interface{})
+	if len(systemSettingsList) == 0 {
+		return nil
+	}
+
+	systemSettingsMap, ok := systemSettingsList[0].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if v, ok := systemSettingsMap["use_disk_threshold"]; ok {
+		systemSettings.UseDiskThreshold = v.(bool)
+	}
+
+	return nil
+}
+
+func expandKibanaClusterPlan(kibanaPlan *KibanaClusterPlan, inputPlan interface{}) error {
+	if inputPlan == nil {
+		return nil
+	}
+
+	kibanaPlanList := inputPlan.([]interface{})
+	if len(kibanaPlanList) == 0 {
+		return nil
+	}
+
+	kibanaPlanMap, ok := kibanaPlanList[0].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if v, ok := kibanaPlanMap["zone_count"]; ok {
+		kibanaPlan.ZoneCount = v.(int)
+	}
+
+	if v, ok := kibanaPlanMap["kibana"]; ok {
+		err := expandKibanaConfiguration(&kibanaPlan.Kibana, v.([]interface{}))
+		if err != nil {
+			return err
+		}
+	}
+
+	return expandKibanaClusterTopology(kibanaPlan, kibanaPlanMap)
+}
+
+func expandKibanaConfiguration(kibanaConfiguration *KibanaConfiguration, kibanaList []interface{}) error {
+	if len(kibanaList) == 0 {
+		return nil
+	}
+
+	kibanaMap, ok := kibanaList[0].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if v, ok := kibanaMap["system_settings"]; ok {
+		systemSettingsList := v.([]interface{})
+		if len(systemSettingsList) > 0 && systemSettingsList[0] != nil {
+			systemSettingsMap := systemSettingsList[0].(map[string]interface{})
+			kibanaConfiguration.SystemSettings = &KibanaSystemSettings{
+				ElasticsearchURL:      systemSettingsMap["elasticsearch_url"].(string),
+				ElasticsearchUsername: systemSettingsMap["elasticsearch_username"].(string),
+			}
+		}
+	}
+
+	if v, ok := kibanaMap["user_settings_json"]; ok && v.(string) != "" {
+		userSettings, err := structure.ExpandJsonFromString(v.(string))
+		if err != nil {
+			return fmt.Errorf("kibana user_settings_json is not valid JSON: %v", err)
+		}
+		kibanaConfiguration.UserSettingsJSON = userSettings
+	}
+
+	if v, ok := kibanaMap["user_settings_override_json"]; ok && v.(string) != "" {
+		userSettings, err := structure.ExpandJsonFromString(v.(string))
+		if err != nil {
+			return fmt.Errorf("kibana user_settings_override_json is not valid JSON: %v", err)
+		}
+		kibanaConfiguration.UserSettingsOverrideJSON = userSettings
+	}
+
+	if v, ok := kibanaMap["user_settings_override_yaml"]; ok {
+		kibanaConfiguration.UserSettingsOverrideYAML = v.(string)
+	}
+
+	if v, ok := kibanaMap["user_settings_yaml"]; ok {
+		kibanaConfiguration.UserSettingsYAML = v.(string)
+	}
+
+	if v, ok := kibanaMap["version"]; ok && v.(string) != "" {
+		kibanaConfiguration.Version = v.(string)
+	}
+
+	return nil
+}
+
+func expandKibanaClusterTopology(kibanaPlan *KibanaClusterPlan, kibanaPlanMap map[string]interface{}) error {
+	var inputClusterTopologyMap []interface{}
+
+	if v, ok := kibanaPlanMap["cluster_topology"]; ok {
+		inputClusterTopologyMap = v.([]interface{})
+	}
+
+	if inputClusterTopologyMap == nil {
+		return nil
+	}
+
+	clusterTopology := make([]KibanaClusterTopologyElement, 0)
+
+	for i, t := range inputClusterTopologyMap {
+		elementMap := t.(map[string]interface{})
+		clusterTopologyElement := baseKibanaClusterTopologyElement(kibanaPlan.ClusterTopology, i)
+
+		if v, ok := elementMap["instance_configuration_id"]; ok && v.(string) != "" {
+			clusterTopologyElement.InstanceConfigurationID = v.(string)
+		}
+
+		if v, ok := elementMap["kibana"]; ok {
+			kibanaList := v.([]interface{})
+			if len(kibanaList) > 0 {
+				kibanaConfiguration := &KibanaConfiguration{}
+				err := expandKibanaConfiguration(kibanaConfiguration, kibanaList)
+				if err != nil {
+					return fmt.Errorf("cluster_topology.%d: %v", i, err)
+				}
+				clusterTopologyElement.Kibana = kibanaConfiguration
+			}
+		}
+
+		if v, ok := elementMap["memory_per_node"]; ok && v.(int) > 0 {
+			clusterTopologyElement.MemoryPerNode = v.(int)
+		}
+
+		if v, ok := elementMap["node_count_per_zone"]; ok && v.(int) > 0 {
+			clusterTopologyElement.NodeCountPerZone = v.(int)
+		}
+
+		if v, ok := elementMap["zone_count"]; ok && v.(int) > 0 {
+			clusterTopologyElement.ZoneCount = v.(int)
+		}
+
+		clusterTopology = append(clusterTopology, *clusterTopologyElement)
+	}
+
+	// Keep the base topology if none is provided in the input map.
+	if len(clusterTopology) == 0 {
+		return nil
+	}
+
+	kibanaPlan.ClusterTopology = clusterTopology
+
+	return nil
+}
+
+// baseKibanaClusterTopologyElement returns the element to use as the base for the input topology element at the
+// specified index. Values that are not set in the base element use the provider defaults.
+func baseKibanaClusterTopologyElement(baseTopology []KibanaClusterTopologyElement, index int) *KibanaClusterTopologyElement {
+	defaultElement := DefaultKibanaClusterTopologyElement()
+
+	if index >= len(baseTopology) {
+		return defaultElement
+	}
+
+	clusterTopologyElement := baseTopology[index]
+
+	if clusterTopologyElement.MemoryPerNode == 0 {
+		clusterTopologyElement.MemoryPerNode = defaultElement.MemoryPerNode
+	}
+
+	if clusterTopologyElement.NodeCountPerZone == 0 {
+		clusterTopologyElement.NodeCountPerZone = defaultElement.NodeCountPerZone
+	}
+
+	if clusterTopologyElement.ZoneCount == 0 {
+		clusterTopologyElement.ZoneCount = defaultElement.ZoneCount
+	}
+
+	return &clusterTopologyElement
+}
+
+func expandKibanaCreateRequest(d resourceDataGetter, meta interface{}) (kibanaRequest *CreateKibanaRequest, err error) {
+	kibanaList := d.Get("kibana").([]interface{})
+
+	if kibanaList == nil || len(kibanaList) == 0 {
+		log.Printf("[DEBUG] Kibana configuration not specified. No Kibana instance will be created.\n")
+		return nil, nil
+	}
+
+	// Use the Kibana plan from the deployment template, if any, as the base for the Kibana plan.
+	kibanaPlan := DefaultKibanaClusterPlan()
+	deploymentTemplate, err := getDeploymentTemplate(d, meta)
+	if err != nil {
+		return nil, err
+	} else if deploymentTemplate != nil && deploymentTemplate.ClusterTemplate.Kibana != nil && deploymentTemplate.ClusterTemplate.Kibana.Plan != nil {
+		kibanaPlan = deploymentTemplate.ClusterTemplate.Kibana.Plan
+	}
+
+	var kibanaName string
+	if kibanaList[0] == nil {
+		log.Printf("[DEBUG] Empty Kibana configuration specified. A default Kibana instance will be created.\n")
+	} else {
+		kibanaMap := kibanaList[0].(map[string]interface{})
+
+		if v, ok := kibanaMap["cluster_name"]; ok {
+			kibanaName = v.(string)
+		}
+
+		if v, ok := kibanaMap["plan"]; ok {
+			err := expandKibanaClusterPlan(kibanaPlan, v.(interface{}))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Keep the Kibana version in lockstep with the Elasticsearch version unless the user has opted out.
+	if kibanaVersionFollowsElasticsearch(d) {
+		kibanaPlan.Kibana.Version = d.Get("plan.0.elasticsearch.0.version").(string)
+	}
+
+	kibanaRequest = &CreateKibanaRequest{
+		ClusterName: kibanaName,
+		Plan:        kibanaPlan,
+	}
+
+	return kibanaRequest, nil
+}
+
+func expandElasticsearchNodeTypeFromMap(nodeType *ElasticsearchNodeType, nodeTypeMap map[string]interface{}) {
+	if v, ok := nodeTypeMap["data"]; ok {
+		nodeType.Data = v.(bool)
+		log.Printf("[DEBUG] Expanded node_type.data as: %t\n", nodeType.Data)
+	}
+
+	if v, ok := nodeTypeMap["ingest"]; ok {
+		nodeType.Ingest = v.(bool)
+		log.Printf("[DEBUG] Expanded node_type.ingest as: %t\n", nodeType.Ingest)
+	}
+
+	if v, ok := nodeTypeMap["master"]; ok {
+		nodeType.Master = v.(bool)
+		log.Printf("[DEBUG] Expanded node_type.master as: %t\n", nodeType.Master)
+	}
+
+	if v, ok := nodeTypeMap["ml"]; ok {
+		nodeType.ML = v.(bool)
+		log.Printf("[DEBUG] Expanded node_type.ml as: %t\n", nodeType.ML)
+	}
+}
+
+func flattenClusterMetadataTags(tags *[]MetadataItem) map[string]interface{} {
+	tagsMap := make(map[string]interface{})
+
+	if tags != nil {
+		for _, t := range *tags {
+			tagsMap[t.Key] = t.Value
+		}
+	}
+
+	return tagsMap
+}
+
+// flattenClusterEndpoint returns the URL of a cluster for the specified scheme, preferring the aliased endpoint.
+// An empty string is returned if the endpoint or port is not yet known.
+func flattenClusterEndpoint(scheme string, metadata ClusterMetadataInfo) string {
+	endpoint := metadata.AliasedEndpoint
+	if endpoint == "" {
+		endpoint = metadata.Endpoint
+	}
+
+	port := metadata.Ports.HTTPS
+	if scheme == "http" {
+		port = metadata.Ports.HTTP
+	}
+
+	if endpoint == "" || port == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%s://%s:%d", scheme, endpoint, port)
+}
+
+func flattenClusterInstances(instances []ClusterInstanceInfo) []map[string]interface{} {
+	instanceMaps := make([]map[string]interface{}, 0)
+
+	for _, instance := range instances {
+		instanceMap := make(map[string]interface{})
+
+		instanceMap["instance_name"] = instance.InstanceName
+		instanceMap["zone"] = instance.Zone
+		instanceMap["allocator_id"] = instance.AllocatorID
+		instanceMap["healthy"] = instance.Healthy
+		instanceMap["memory_capacity"] = instance.Memory.InstanceCapacity
+		instanceMap["disk_space_available"] = int(instance.Disk.DiskSpaceAvailable)
+		instanceMap["disk_space_used"] = int(instance.Disk.DiskSpaceUsed)
+		instanceMap["service_roles"] = instance.ServiceRoles
+		instanceMap["maintenance_mode"] = instance.MaintenanceMode
+
+		instanceMaps = append(instanceMaps, instanceMap)
+	}
+
+	logJSON("Flattened cluster instances", instanceMaps)
+
+	return instanceMaps
+}
+
+func flattenClusterMetadataPorts(ports ClusterMetadataPortInfo) []map[string]interface{} {
+	portsMaps := make([]map[string]interface{}, 1)
+
+	portsMap := make(map[string]interface{})
+	portsMap["http"] = ports.HTTP
+	portsMap["https"] = ports.HTTPS
+
+	portsMaps[0] = portsMap
+
+	return portsMaps
+}
+
+// flattenElasticsearchClusterMetadataRaw returns the subset of the raw cluster metadata whose keys appear
+// in the managed JSON document, so that keys maintained by ECE itself do not show up as drift.
+func flattenElasticsearchClusterMetadataRaw(rawMetadata map[string]interface{}, managedJSON string) (string, error) {
+	managedMetadata, err := expandJSONObject(managedJSON)
+	if err != nil {
+		return "", err
+	}
+
+	if len(managedMetadata) == 0 {
+		return managedJSON, nil
+	}
+
+	flattenedMetadata := make(map[string]interface{})
+	for k := range managedMetadata {
+		if v, ok := rawMetadata[k]; ok {
+			flattenedMetadata[k] = v
+		}
+	}
+
+	return structure.FlattenJsonToString(flattenedMetadata)
+}
+
+// flattenDesiredState returns the desired state that corresponds to the status of a cluster. Clusters that are
+// stopping are considered stopped, and clusters in any other status are considered started.
+func flattenDesiredState(status string) string {
+	if status == "stopped" || status == "stopping" {
+		return "stopped"
+	}
+
+	return "started"
+}
+
+// flattenElasticsearchClusterMonitoring returns the monitoring block for the monitoring destination reported by ECE.
+// ECE does not report whether logs and metrics are sent, so the previously applied values are only kept while the
+// destination is unchanged. A destination set outside of Terraform is assumed to receive both.
+func flattenElasticsearchClusterMonitoring(monitoringInfo *ElasticsearchMonitoringInfo, previousMonitoring []interface{}) []map[string]interface{} {
+	if monitoringInfo == nil || len(monitoringInfo.DestinationClusterIDs) < 1 {
+		return []map[string]interface{}{}
+	}
+
+	monitoringMap := map[string]interface{}{
+		"target_cluster_id": monitoringInfo.DestinationClusterIDs[0],
+		"logs":              true,
+		"metrics":           true,
+	}
+
+	targetClusterID, logs, metrics := expandElasticsearchClusterMonitoring(previousMonitoring)
+	if targetClusterID == monitoringMap["target_cluster_id"] {
+		monitoringMap["logs"] = logs
+		monitoringMap["metrics"] = metrics
+	}
+
+	return []map[string]interface{}{monitoringMap}
+}
+
+// flattenElasticsearchClusterPlan returns the current cluster plan. The plan zone count is only set when the previous
+// plan zone count is set, so that it is not kept as a topology default after it is removed from the configuration.
+// Topology elements that are placed in the plan number of zones then have no zone count of their own.
+func flattenElasticsearchClusterPlan(clusterInfo ElasticsearchClusterInfo, previousZoneCount int) []map[string]interface{} {
+	clusterPlanMaps := make([]map[string]interface{}, 1)
+
+	clusterPlan := clusterInfo.PlanInfo.Current.Plan
+
+	zoneCount := 0
+	if previousZoneCount > 0 {
+		zoneCount = flattenElasticsearchClusterZoneCount(clusterPlan)
+	}
+
+	clusterPlanMap := make(map[string]interface{})
+	clusterPlanMap["cluster_topology"] = flattenElasticsearchClusterTopology(clusterPlan, zoneCount)
+	clusterPlanMap["elasticsearch"] = flattenElasticsearchConfiguration(clusterPlan.Elasticsearch)
+	clusterPlanMap["zone_count"] = zoneCount
+
+	clusterPlanMaps[0] = clusterPlanMap
+
+	return clusterPlanMaps
+}
+
+func flattenElasticsearchClusterTopology(clusterPlan ElasticsearchClusterPlan, planZoneCount int) []map[string]interface{} {
+	topologyMap := make([]map[string]interface{}, 0)
+
+	// NOTE: This property appears as deprecated in the ECE API documentation, recommending use of the zone count from the
+	// ElasticsearchClusterTopologyElement instead. However, zone count is not returned for ElasticsearchClusterTopologyElement
+	// in the current version of ECE (2.2.3). To support either location, the zone count is used from cluster plan unless the
+	// cluster topology element has a non-zero value.
+	// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ElasticsearchClusterPlan
+	defaultZoneCount := clusterPlan.ZoneCount
+
+	for _, t := range clusterPlan.ClusterTopology {
+		elementMap := make(map[string]interface{})
+
+		elementMap["allocator_filter"] = ""
+		if len(t.AllocatorFilter) > 0 {
+			allocatorFilter, err := structure.FlattenJsonToString(t.AllocatorFilter)
+			if err != nil {
+				log.Printf("[DEBUG] Error flattening allocator_filter as JSON: %v\n", err)
+			} else {
+				elementMap["allocator_filter"] = allocatorFilter
+			}
+		}
+
+		elementMap["elasticsearch"] = flattenElasticsearchTopologyConfiguration(t.Elasticsearch)
+		elementMap["instance_configuration_id"] = t.InstanceConfigurationID
+		elementMap["memory_per_node"] = t.MemoryPerNode
+		elementMap["node_attributes"] = t.NodeAttributes
+		elementMap["node_count_per_zone"] = t.NodeCountPerZone
+
+		elementMap["node_type"] = flattenElasticsearchNodeType(t.NodeType)
+
+		// See note above about clusterPlan.ZoneCount. Elements that use the plan zone count from the configuration
+		// have no zone count of their own.
+		elementZoneCount := defaultZoneCount
+		if t.ZoneCount > 0 {
+			elementZoneCount = t.ZoneCount
+		}
+
+		if planZoneCount > 0 && elementZoneCount == planZoneCount {
+			elementZoneCount = 0
+		}
+		elementMap["zone_count"] = elementZoneCount
+
+		topologyMap = append(topologyMap, elementMap)
+	}
+
+	logJSON("Flattened cluster topology", topologyMap)
+
+	return topologyMap
+}
+
+// flattenElasticsearchClusterZoneCount returns the plan zone count, or the zone count of the topology elements if all
+// elements are placed in the same number of zones. See note in flattenElasticsearchClusterTopology.
+func flattenElasticsearchClusterZoneCount(clusterPlan ElasticsearchClusterPlan) int {
+	if clusterPlan.ZoneCount > 0 {
+		return clusterPlan.ZoneCount
+	}
+
+	zoneCount := 0
+	for _, t := range clusterPlan.ClusterTopology {
+		if zoneCount != 0 && t.ZoneCount != zoneCount {
+			return 0
+		}
+		zoneCount = t.ZoneCount
+	}
+
+	return zoneCount
+}
+
+func flattenElasticsearchClusterRemoteClusters(remoteClusters []RemoteClusterInfo) []map[string]interface{} {
+	remoteClusterMaps := make([]map[string]interface{}, 0, len(remoteClusters))
+
+	for _, r := range remoteClusters {
+		remoteClusterMaps = append(remoteClusterMaps, map[string]interface{}{
+			"alias":             r.Alias,
+			"target_cluster_id": r.ElasticsearchID,
+			"skip_unavailable":  r.SkipUnavailable,
+		})
+	}
+
+	return remoteClusterMaps
+}
+
+func flattenElasticsearchConfiguration(configuration ElasticsearchConfiguration) []map[string]interface{} {
+	elasticsearchMaps := make([]map[string]interface{}, 1)
+
+	systemSettings := configuration.SystemSettings
+	if systemSettings == nil {
+		systemSettings = DefaultElasticsearchSystemSettings()
+	}
+
+	elasticsearchMap := flattenElasticsearchUserSettings(configuration)
+	elasticsearchMap["version"] = configuration.Version
+	elasticsearchMap["system_settings"] = flattenElasticsearchSystemSettings(*systemSettings)
+
+	elasticsearchMaps[0] = elasticsearchMap
+
+	logJSON("Flattened elasticsearch configuration", elasticsearchMaps)
+
+	return elasticsearchMaps
+}
+
+// flattenElasticsearchTopologyConfiguration flattens the Elasticsearch settings of a topology element. No settings
+// are returned unless a user setting overrides the plan-level settings.
+func flattenElasticsearchTopologyConfiguration(configuration *ElasticsearchConfiguration) []map[string]interface{} {
+	if configuration == nil {
+		return make([]map[string]interface{}, 0)
+	}
+
+	if len(configuration.UserSettingsJSON) == 0 && len(configuration.UserSettingsOverrideJSON) == 0 &&
+		configuration.UserSettingsOverrideYAML == "" && configuration.UserSettingsYAML == "" {
+		return make([]map[string]interface{}, 0)
+	}
+
+	return []map[string]interface{}{flattenElasticsearchUserSettings(*configuration)}
+}
+
+func flattenElasticsearchUserSettings(configuration ElasticsearchConfiguration) map[string]interface{} {
+	elasticsearchMap := make(map[string]interface{})
+
+	elasticsearchMap["user_settings_json"] = flattenJSONObject(configuration.UserSettingsJSON)
+	elasticsearchMap["user_settings_override_json"] = flattenJSONObject(configuration.UserSettingsOverrideJSON)
+	elasticsearchMap["user_settings_override_yaml"] = configuration.UserSettingsOverrideYAML
+	elasticsearchMap["user_settings_yaml"] = configuration.UserSettingsYAML
+
+	return elasticsearchMap
+}
+
+// flattenElasticsearchTemplateUserSettings clears the plan-level user settings that have the deployment template value
+// and are not set in the previous plan, since the template value is used when a setting is not configured. User
+// settings that are removed from the configuration are still shown as a change.
+func flattenElasticsearchTemplateUserSettings(d *schema.ResourceData, meta interface{}, elasticsearchMaps []map[string]interface{}) error {
+	deploymentTemplate, err := getDeploymentTemplate(d, meta)
+	if err != nil || deploymentTemplate == nil {
+		return err
+	}
+
+	templateSettings := flattenElasticsearchUserSettings(deploymentTemplate.ClusterTemplate.Plan.Elasticsearch)
+	for k, templateValue := range templateSettings {
+		if templateValue != "" && elasticsearchMaps[0][k] == templateValue && d.Get("plan.0.elasticsearch.0."+k).(string) == "" {
+			elasticsearchMaps[0][k] = ""
+		}
+	}
+
+	return nil
+}
+
+// flattenElasticsearchNodeType flattens the node type of a topology element from the cluster plan. The node type is
+// not derived from the cluster instances, since instances and topology elements do not map one-to-one.
+func flattenElasticsearchNodeType(nodeType ElasticsearchNodeType) []map[string]interface{} {
+	nodeTypeMaps := make([]map[string]interface{}, 1)
+
+	nodeTypeMap := make(map[string]interface{})
+
+	nodeTypeMap["data"] = nodeType.Data
+	log.Printf("[DEBUG] Flattened node_type.data as: %t\n", nodeTypeMap["data"])
+
+	nodeTypeMap["ingest"] = nodeType.Ingest
+	log.Printf("[DEBUG] Flattened node_type.ingest as: %t\n", nodeTypeMap["ingest"])
+
+	nodeTypeMap["master"] = nodeType.Master
+	log.Printf("[DEBUG] Flattened node_type.master as: %t\n", nodeTypeMap["master"])
+
+	nodeTypeMap["ml"] = nodeType.ML
+	log.Printf("[DEBUG] Flattened node_type.ml as: %t\n", nodeTypeMap["ml"])
+
+	nodeTypeMaps[0] = nodeTypeMap
+
+	return nodeTypeMaps
+}
+
+func flattenElasticsearchSystemSettings(systemSettings ElasticsearchSystemSettings) []map[string]interface{} {
+	systemSettingsMaps := make([]map[string]interface{}, 1)
+
+	systemSettingsMap := make(map[string]interface{})
+	systemSettingsMap["use_disk_threshold"] = systemSettings.UseDiskThreshold
+
+	systemSettingsMaps[0] = systemSettingsMap
+
+	logJSON("Flattened elasticsearch system settings", systemSettingsMaps)
+
+	return systemSettingsMaps
+}
+
+// parseElasticsearchClusterImportID returns the Elasticsearch cluster ID or name and the optional Kibana cluster ID
+// from an import ID of the form <cluster_id_or_name>[:<kibana_cluster_id>].
+func parseElasticsearchClusterImportID(importID string) (clusterRef string, kibanaClusterID string) {
+	if i := strings.Index(importID, ":"); i >= 0 {
+		return importID[:i], importID[i+1:]
+	}
+
+	return importID, ""
+}
+
+// importedKibanaClusterID returns the ID of the Kibana cluster to import with an Elasticsearch cluster. The specified
+// Kibana cluster must be associated with the Elasticsearch cluster. If none is specified, the first associated Kibana
+// cluster is used, if any.
+func importedKibanaClusterID(clusterInfo *ElasticsearchClusterInfo, kibanaClusterID string) (string, error) {
+	if kibanaClusterID == "" {
+		if len(clusterInfo.AssociatedKibanaClusters) == 0 {
+			return "", nil
+		}
+
+		return clusterInfo.AssociatedKibanaClusters[0].KibanaID, nil
+	}
+
+	for _, k := range clusterInfo.AssociatedKibanaClusters {
+		if k.KibanaID == kibanaClusterID {
+			return kibanaClusterID, nil
+		}
+	}
+
+	return "", fmt.Errorf("%q: Kibana cluster is not associated with elasticsearch cluster %q", kibanaClusterID, clusterInfo.ClusterID)
+}
+
+// findElasticsearchCluster returns the Elasticsearch cluster with the specified ID or, if no cluster has that ID, the
+// single Elasticsearch cluster with the specified name.
+func findElasticsearchCluster(client *ECEClient, clusterRef string) (*ElasticsearchClusterInfo, error) {
+	resp, err := client.GetElasticsearchCluster(clusterRef)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == 200 {
+		var clusterInfo ElasticsearchClusterInfo
+		err = json.NewDecoder(resp.Body).Decode(&clusterInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		return &clusterInfo, nil
+	}
+
+	log.Printf("[DEBUG] Elasticsearch cluster ID not found, searching by cluster name: %s\n", clusterRef)
+
+	resp, err = client.GetElasticsearchClusters()
+	if err != nil {
+		return nil, err
+	}
+
+	var clustersInfo ElasticsearchClustersInfo
+	err = json.NewDecoder(resp.Body).Decode(&clustersInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []ElasticsearchClusterInfo
+	for _, c := range clustersInfo.ElasticsearchClusters {
+		if c.ClusterName == clusterRef {
+			matches = append(matches, c)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%q: no elasticsearch cluster found with this ID or name", clusterRef)
+	case 1:
+		// The cluster list does not include the cluster plans, so the matching cluster is retrieved by its ID.
+		return findElasticsearchCluster(client, matches[0].ClusterID)
+	default:
+		clusterIDs := make([]string, len(matches))
+		for i, c := range matches {
+			clusterIDs[i] = c.ClusterID
+		}
+		return nil, fmt.Errorf("%q: multiple elasticsearch clusters found with this name, import by cluster ID instead: %s", clusterRef, strings.Join(clusterIDs, ", "))
+	}
+}
+
+// getDeploymentTemplate returns the deployment template specified by deployment_template_id, or nil if no
+// deployment template is specified.
+func getDeploymentTemplate(d resourceDataGetter, meta interface{}) (*DeploymentTemplateInfo, error) {
+	templateID := d.Get("deployment_template_id").(string)
+	if templateID == "" {
+		return nil, nil
+	}
+
+	client := meta.(*ECEClient)
+	client.deploymentTemplatesLock.Lock()
+	defer client.deploymentTemplatesLock.Unlock()
+
+	// The template is only read from ECE once, since the same template is used by every expand of an operation.
+	templateBytes, ok := client.deploymentTemplates[templateID]
+	if !ok {
+		log.Printf("[DEBUG] Reading deployment template ID: %s\n", templateID)
+
+		resp, err := client.GetDeploymentTemplate(templateID)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == 404 {
+			return nil, fmt.Errorf("%q: deployment template ID was not found", templateID)
+		}
+
+		templateBytes, err = ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		if client.deploymentTemplates == nil {
+			client.deploymentTemplates = make(map[string][]byte)
+		}
+
+		client.deploymentTemplates[templateID] = templateBytes
+	}
+
+	// Each call decodes a new copy of the template, since the callers use it as the base of the plans they modify.
+	var deploymentTemplate DeploymentTemplateInfo
+	err := json.Unmarshal(templateBytes, &deploymentTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	logJSON("Deployment template cluster plan", deploymentTemplate.ClusterTemplate.Plan)
+
+	return &deploymentTemplate, nil
+}
+
+// kibanaVersionFollowsElasticsearch returns whether the Kibana version should be kept in lockstep with the
+// Elasticsearch version.
+func kibanaVersionFollowsElasticsearch(d resourceDataGetter) bool {
+	kibanaList := d.Get("kibana").([]interface{})
+	if len(kibanaList) == 0 {
+		return false
+	}
+
+	// An empty Kibana block uses the default, which is to follow the Elasticsearch version.
+	kibanaMap, ok := kibanaList[0].(map[string]interface{})
+	if !ok {
+		return true
+	}
+
+	if v, ok := kibanaMap["upgrade_with_elasticsearch"]; ok {
+		return v.(bool)
+	}
+
+	return true
+}
+
+func flattenKibanaCluster(kibanaInfo KibanaClusterInfo, upgradeWithElasticsearch bool) []map[string]interface{} {
+	kibanaMaps := make([]map[string]interface{}, 1)
+
+	kibanaMap := make(map[string]interface{})
+	kibanaMap["cluster_name"] = kibanaInfo.ClusterName
+	kibanaMap["desired_state"] = flattenDesiredState(kibanaInfo.Status)
+	kibanaMap["upgrade_with_elasticsearch"] = upgradeWithElasticsearch
+	kibanaMap["plan"] = flattenKibanaClusterPlan(kibanaInfo.PlanInfo.Current.Plan)
+
+	kibanaMaps[0] = kibanaMap
+
+	return kibanaMaps
+}
+
+func flattenKibanaClusterPlan(kibanaPlan KibanaClusterPlan) []map[string]interface{} {
+	kibanaPlanMaps := make([]map[string]interface{}, 1)
+
+	kibanaPlanMap := make(map[string]interface{})
+	kibanaPlanMap["kibana"] = flattenKibanaConfiguration(kibanaPlan.Kibana, true)
+	kibanaPlanMap["cluster_topology"] = flattenKibanaClusterTopology(kibanaPlan)
+
+	kibanaPlanMaps[0] = kibanaPlanMap
+
+	logJSON("Flattened Kibana plan", kibanaPlanMaps)
+
+	return kibanaPlanMaps
+}
+
+func flattenKibanaClusterTopology(kibanaPlan KibanaClusterPlan) []map[string]interface{} {
+	topologyMap := make([]map[string]interface{}, 0)
+
+	for _, t := range kibanaPlan.ClusterTopology {
+		elementMap := make(map[string]interface{})
+
+		elementMap["instance_configuration_id"] = t.InstanceConfigurationID
+		elementMap["memory_per_node"] = t.MemoryPerNode
+		elementMap["node_count_per_zone"] = t.NodeCountPerZone
+
+		elementMap["kibana"] = make([]map[string]interface{}, 0)
+		if t.Kibana != nil {
+			elementMap["kibana"] = flattenKibanaConfiguration(*t.Kibana, false)
+		}
+
+		// See note in flattenElasticsearchClusterTopology about the plan zone count.
+		if t.ZoneCount > 0 {
+			elementMap["zone_count"] = t.ZoneCount
+		} else {
+			elementMap["zone_count"] = kibanaPlan.ZoneCount
+		}
+
+		topologyMap = append(topologyMap, elementMap)
+	}
+
+	return topologyMap
+}
+
+func flattenKibanaConfiguration(kibanaConfiguration KibanaConfiguration, includeVersion bool) []map[string]interface{} {
+	kibanaMaps := make([]map[string]interface{}, 1)
+
+	kibanaMap := make(map[string]interface{})
+
+	kibanaMap["system_settings"] = make([]map[string]interface{}, 0)
+	if kibanaConfiguration.SystemSettings != nil {
+		kibanaMap["system_settings"] = []map[string]interface{}{
+			{
+				"elasticsearch_url":      kibanaConfiguration.SystemSettings.ElasticsearchURL,
+				"elasticsearch_username": kibanaConfiguration.SystemSettings.ElasticsearchUsername,
+			},
+		}
+	}
+
+	kibanaMap["user_settings_json"] = flattenJSONObject(kibanaConfiguration.UserSettingsJSON)
+	kibanaMap["user_settings_override_json"] = flattenJSONObject(kibanaConfiguration.UserSettingsOverrideJSON)
+	kibanaMap["user_settings_override_yaml"] = kibanaConfiguration.UserSettingsOverrideYAML
+	kibanaMap["user_settings_yaml"] = kibanaConfiguration.UserSettingsYAML
+
+	if includeVersion {
+		kibanaMap["version"] = kibanaConfiguration.Version
+	}
+
+	kibanaMaps[0] = kibanaMap
+
+	return kibanaMaps
+}
+
+func logJSON(context string, m interface{}) {
+	jsonBytes, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		log.Printf("[DEBUG] %s: error marshalling value as JSON: %s. %v", context, err, m)
+	}
+
+	log.Printf("[DEBUG] %s: %s", context, string(jsonBytes))
+}
+
+func readElasticsearchClusterRemoteClusters(client *ECEClient, clusterID string, d *schema.ResourceData) error {
+	resp, err := client.GetElasticsearchClusterCCSSettings(clusterID)
+	if err != nil {
+		return err
+	}
+
+	// Clusters that are not cross-cluster search clusters have no remote clusters.
+	var settings CrossClusterSearchSettings
+	if resp.StatusCode == 200 {
+		err = json.NewDecoder(resp.Body).Decode(&settings)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Keep the order of the remote clusters in the state so that a different order from ECE is not a change.
+	aliasIndex := make(map[string]int)
+	for i, r := range d.Get("remote_clusters").([]interface{}) {
+		aliasIndex[r.(map[string]interface{})["alias"].(string)] = i
+	}
+
+	sort.SliceStable(settings.Remotes, func(i, j int) bool {
+		iIndex, iOk := aliasIndex[settings.Remotes[i].Alias]
+		jIndex, jOk := aliasIndex[settings.Remotes[j].Alias]
+		if iOk && jOk {
+			return iIndex < jIndex
+		}
+		return iOk && !jOk
+	})
+
+	remoteClusters := flattenElasticsearchClusterRemoteClusters(settings.Remotes)
+	log.Printf("[DEBUG] Setting elasticsearch cluster remote_clusters: %v\n", remoteClusters)
+	return d.Set("remote_clusters", remoteClusters)
+}
+
+func readKibanaCluster(client *ECEClient, kibanaClusterID string, d *schema.ResourceData) error {
+	log.Printf("[DEBUG] Reading Kibana cluster information for cluster ID: %s\n", kibanaClusterID)
+
+	resp, err := client.GetKibanaCluster(kibanaClusterID)
+	if err != nil {
+		return err
+	}
+
+	// If the Kibana cluster no longer exists, clear it from the state so that it is recreated.
+	if resp.StatusCode == 404 {
+		log.Printf("[DEBUG] Kibana cluster ID not found: %s\n", kibanaClusterID)
+		d.Set("kibana", nil)
+		d.Set("kibana_cluster_id", "")
+		d.Set("kibana_https_endpoint", "")
+		return nil
+	}
+
+	var kibanaInfo KibanaClusterInfo
+	err = json.NewDecoder(resp.Body).Decode(&kibanaInfo)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Setting Kibana cluster endpoint: %v\n", kibanaInfo.Metadata)
+	d.Set("kibana_https_endpoint", flattenClusterEndpoint("https", kibanaInfo.Metadata))
+
+	// Only refresh the Kibana configuration if it is managed by this resource.
+	if kibanaList := d.Get("kibana").([]interface{}); len(kibanaList) == 0 {
+		return nil
+	}
+
+	kibana := flattenKibanaCluster(kibanaInfo, kibanaVersionFollowsElasticsearch(d))
+	log.Printf("[DEBUG] Setting Kibana cluster: %v\n", kibana)
+	return d.Set("kibana", kibana)
+}
+
+func readElasticsearchClusterMetadata(client *ECEClient, clusterID string, d *schema.ResourceData) error {
+	resp, err := client.GetElasticsearchClusterMetadataSettings(clusterID)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == 200 {
+		var metadata ClusterMetadataSettings
+		err = json.NewDecoder(resp.Body).Decode(&metadata)
+		if err != nil {
+			return err
+		}
+
+		tags := flattenClusterMetadataTags(metadata.Tags)
+		log.Printf("[DEBUG] Setting elasticsearch cluster tags: %v\n", tags)
+		d.Set("tags", tags)
+	}
+
+	resp, err = client.GetElasticsearchClusterMetadataRaw(clusterID)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == 200 {
+		var rawMetadata map[string]interface{}
+		err = json.NewDecoder(resp.Body).Decode(&rawMetadata)
+		if err != nil {
+			return err
+		}
+
+		metadataRawJSON, err := flattenElasticsearchClusterMetadataRaw(rawMetadata, d.Get("metadata_raw_json").(string))
+		if err != nil {
+			return err
+		}
+
+		log.Printf("[DEBUG] Setting elasticsearch cluster metadata_raw_json: %v\n", metadataRawJSON)
+		d.Set("metadata_raw_json", metadataRawJSON)
+
+		deletionProtection, _ := rawMetadata[deletionProtectionMetadataKey].(bool)
+		d.Set("deletion_protection", deletionProtection)
+	}
+
+	return nil
+}
+
+// updateElasticsearchClusterDeletionProtection sets or removes the deletion protection key in the raw cluster metadata,
+// keeping the other keys.
+func updateElasticsearchClusterDeletionProtection(client *ECEClient, clusterID string, deletionProtection bool) error {
+	resp, err := client.GetElasticsearchClusterMetadataRaw(clusterID)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == 404 {
+		return fmt.Errorf("%q: cluster ID was not found for raw metadata update", clusterID)
+	}
+
+	var rawMetadata map[string]interface{}
+	err = json.NewDecoder(resp.Body).Decode(&rawMetadata)
+	if err != nil {
+		return err
+	}
+
+	if rawMetadata == nil {
+		rawMetadata = make(map[string]interface{})
+	}
+
+	if deletionProtection {
+		rawMetadata[deletionProtectionMetadataKey] = true
+	} else {
+		delete(rawMetadata, deletionProtectionMetadataKey)
+	}
+
+	log.Printf("[DEBUG] Setting deletion protection to %t for elasticsearch cluster ID: %s\n", deletionProtection, clusterID)
+	_, err = client.UpdateElasticsearchClusterMetadataRaw(clusterID, rawMetadata)
+	return err
+}
+
+// updateElasticsearchClusterMonitoring sends the monitoring data of an Elasticsearch cluster to the configured
+// monitoring cluster, or stops sending it if monitoring was removed, and waits for the change to be applied.
+func updateElasticsearchClusterMonitoring(client *ECEClient, clusterID string, d *schema.ResourceData) error {
+	targetClusterID, logs, metrics := expandElasticsearchClusterMonitoring(d.Get("monitoring").([]interface{}))
 
-		return kibanaRequest, nil
+	var err error
+	oldTargetClusterID := ""
+	if o, _ := d.GetChange("monitoring.0.target_cluster_id"); o != nil {
+		oldTargetClusterID = o.(string)
 	}
 
-	var kibanaName string
-	kibanaMap := kibanaList[0].(map[string]interface{})
-
-	if v, ok := kibanaMap["cluster_name"]; ok {
-		kibanaName = v.(string)
+	// Stop sending data to the previous monitoring cluster when monitoring is removed or moved to another cluster.
+	if oldTargetClusterID != "" && oldTargetClusterID != targetClusterID {
+		log.Printf("[DEBUG] Cancelling monitoring to cluster %s for elasticsearch cluster ID: %s\n", oldTargetClusterID, clusterID)
+		_, err = client.CancelElasticsearchClusterMonitoring(clusterID)
+		if err != nil {
+			return err
+		}
 	}
 
-	if v, ok := kibanaMap["plan"]; ok {
-		err := expandKibanaClusterPlan(kibanaPlan, v.(interface{}))
+	if targetClusterID != "" {
+		log.Printf("[DEBUG] Setting monitoring to cluster %s for elasticsearch cluster ID: %s\n", targetClusterID, clusterID)
+		_, err = client.SetElasticsearchClusterMonitoring(clusterID, targetClusterID, logs, metrics)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	kibanaRequest = &CreateKibanaRequest{
-		ClusterName: kibanaName,
-		Plan:        kibanaPlan,
+	// Wait for the change to be initiated.
+	duration := time.Duration(5) * time.Second // 5 seconds
+	time.Sleep(duration)
+
+	err = client.WaitForElasticsearchClusterStatus(clusterID, "started", false)
+	if err != nil {
+		return err
 	}
 
-	return kibanaRequest, nil
+	return validateElasticsearchClusterPlanActivity(client, clusterID)
 }
 
-func expandElasticsearchNodeTypeFromMap(nodeType *ElasticsearchNodeType, nodeTypeMap map[string]interface{}) {
-	if v, ok := nodeTypeMap["data"]; ok {
-		nodeType.Data = v.(bool)
-		log.Printf("[DEBUG] Expanded node_type.data as: %t\n", nodeType.Data)
+// updateElasticsearchClusterRemoteClusters replaces the cross-cluster search remote clusters of an Elasticsearch
+// cluster and waits for the change to be applied.
+func updateElasticsearchClusterRemoteClusters(client *ECEClient, clusterID string, d *schema.ResourceData) error {
+	settings := CrossClusterSearchSettings{
+		Remotes: expandElasticsearchClusterRemoteClusters(d.Get("remote_clusters").([]interface{})),
 	}
 
-	if v, ok := nodeTypeMap["ingest"]; ok {
-		nodeType.Ingest = v.(bool)
-		log.Printf("[DEBUG] Expanded node_type.ingest as: %t\n", nodeType.Ingest)
+	_, err := client.UpdateElasticsearchClusterCCSSettings(clusterID, settings)
+	if err != nil {
+		return err
 	}
 
-	if v, ok := nodeTypeMap["master"]; ok {
-		nodeType.Master = v.(bool)
-		log.Printf("[DEBUG] Expanded node_type.master as: %t\n", nodeType.Master)
-	}
+	// Wait for the change to be initiated.
+	duration := time.Duration(5) * time.Second // 5 seconds
+	time.Sleep(duration)
 
-	if v, ok := nodeTypeMap["ml"]; ok {
-		nodeType.ML = v.(bool)
-		log.Printf("[DEBUG] Expanded node_type.ml as: %t\n", nodeType.ML)
+	err = client.WaitForElasticsearchClusterStatus(clusterID, "started", false)
+	if err != nil {
+		return err
 	}
+
+	return validateElasticsearchClusterPlanActivity(client, clusterID)
 }
 
-func flattenElasticsearchClusterPlan(clusterInfo ElasticsearchClusterInfo) []map[string]interface{} {
-	clusterPlanMaps := make([]map[string]interface{}, 1)
+func updateElasticsearchClusterMetadataRaw(client *ECEClient, clusterID string, d *schema.ResourceData) error {
+	o, n := d.GetChange("metadata_raw_json")
 
-	clusterPlan := clusterInfo.PlanInfo.Current.Plan
+	oldMetadata, err := expandJSONObject(o.(string))
+	if err != nil {
+		return err
+	}
 
-	clusterPlanMap := make(map[string]interface{})
-	clusterPlanMap["cluster_topology"] = flattenElasticsearchClusterTopology(clusterInfo, clusterPlan)
-	clusterPlanMap["elasticsearch"] = flattenElasticsearchConfiguration(clusterPlan.Elasticsearch)
+	newMetadata, err := expandJSONObject(n.(string))
+	if err != nil {
+		return err
+	}
 
-	clusterPlanMaps[0] = clusterPlanMap
+	// The raw metadata endpoint replaces the whole document, so start from the current metadata
+	// and only touch the keys managed by this resource.
+	resp, err := client.GetElasticsearchClusterMetadataRaw(clusterID)
+	if err != nil {
+		return err
+	}
 
-	return clusterPlanMaps
-}
+	if resp.StatusCode == 404 {
+		return fmt.Errorf("%q: cluster ID was not found for raw metadata update", clusterID)
+	}
 
-func flattenElasticsearchClusterTopology(clusterInfo ElasticsearchClusterInfo, clusterPlan ElasticsearchClusterPlan) []map[string]interface{} {
-	topologyMap := make([]map[string]interface{}, 0)
+	var rawMetadata map[string]interface{}
+	err = json.NewDecoder(resp.Body).Decode(&rawMetadata)
+	if err != nil {
+		return err
+	}
 
-	// NOTE: This property appears as deprecated in the ECE API documentation, recommending use of the zone count from the
-	// ElasticsearchClusterTopologyElement instead. However, zone count is not returned for ElasticsearchClusterTopologyElement
-	// in the current version of ECE (2.2.3). To support either location, the zone count is used from cluster plan unless the
-	// cluster topology element has a non-zero value.
-	// See https://www.elastic.co/guide/en/cloud-enterprise/current/definitions.html#ElasticsearchClusterPlan
-	defaultZoneCount := clusterPlan.ZoneCount
+	if rawMetadata == nil {
+		rawMetadata = make(map[string]interface{})
+	}
 
-	for i, t := range clusterPlan.ClusterTopology {
-		elementMap := make(map[string]interface{})
+	for k := range oldMetadata {
+		if _, ok := newMetadata[k]; !ok {
+			delete(rawMetadata, k)
+		}
+	}
 
-		elementMap["instance_configuration_id"] = t.InstanceConfigurationID
-		elementMap["memory_per_node"] = t.MemoryPerNode
-		elementMap["node_count_per_zone"] = t.NodeCountPerZone
+	for k, v := range newMetadata {
+		rawMetadata[k] = v
+	}
 
-		elementMap["node_type"] = flattenElasticsearchNodeType(clusterInfo, i)
+	_, err = client.UpdateElasticsearchClusterMetadataRaw(clusterID, rawMetadata)
+	return err
+}
 
-		// See note above about clusterPlan.ZoneCount.
-		if t.ZoneCount > 0 {
-			elementMap["zone_count"] = t.ZoneCount
-		} else {
-			elementMap["zone_count"] = defaultZoneCount
+// kibanaClusterChanged returns whether the kibana block has changed, ignoring the desired state of the Kibana cluster.
+func kibanaClusterChanged(d *schema.ResourceData) bool {
+	o, n := d.GetChange("kibana")
+	oldKibana, newKibana := o.([]interface{}), n.([]interface{})
+	if len(oldKibana) != len(newKibana) {
+		return true
+	}
+
+	for i := range oldKibana {
+		oldMap, oldOk := oldKibana[i].(map[string]interface{})
+		newMap, newOk := newKibana[i].(map[string]interface{})
+		if !oldOk || !newOk {
+			return !reflect.DeepEqual(oldKibana[i], newKibana[i])
 		}
 
-		topologyMap = append(topologyMap, elementMap)
+		for k := range newMap {
+			if k != "desired_state" && !reflect.DeepEqual(oldMap[k], newMap[k]) {
+				return true
+			}
+		}
 	}
 
-	logJSON("Flattened cluster topology", topologyMap)
-
-	return topologyMap
+	return false
 }
 
-func flattenElasticsearchConfiguration(configuration ElasticsearchConfiguration) []map[string]interface{} {
-	elasticsearchMaps := make([]map[string]interface{}, 1)
+// restartElasticsearchCluster performs a rolling restart of an Elasticsearch cluster using the restart options, and
+// waits for the restart to complete.
+func restartElasticsearchCluster(client *ECEClient, clusterID string, d *schema.ResourceData) error {
+	log.Printf("[DEBUG] Restarting elasticsearch cluster ID: %s\n", clusterID)
 
-	elasticsearchMap := make(map[string]interface{})
-	elasticsearchMap["version"] = configuration.Version
-	elasticsearchMap["system_settings"] = flattenElasticsearchSystemSettings(configuration.SystemSettings)
+	_, err := client.RestartElasticsearchCluster(clusterID, expandElasticsearchClusterRestartParams(d))
+	if err != nil {
+		return err
+	}
 
-	elasticsearchMaps[0] = elasticsearchMap
+	// Wait for the restart plan to be initiated.
+	duration := time.Duration(5) * time.Second // 5 seconds
+	time.Sleep(duration)
 
-	logJSON("Flattened elasticsearch configuration", elasticsearchMaps)
+	err = client.WaitForElasticsearchClusterStatus(clusterID, "started", false)
+	if err != nil {
+		return err
+	}
 
-	return elasticsearchMaps
+	// Confirm that the restart plan was successfully applied.
+	return validateElasticsearchClusterPlanActivity(client, clusterID)
 }
 
-func flattenElasticsearchNodeType(clusterInfo ElasticsearchClusterInfo, instanceIndex int) map[string]interface{} {
-	nodeTypeMap := make(map[string]interface{})
-
-	if len(clusterInfo.Topology.Instances) > 0 {
-		instance := clusterInfo.Topology.Instances[instanceIndex]
-
-		nodeType := &ElasticsearchNodeType{}
+// takeElasticsearchClusterFinalSnapshot takes a snapshot of an Elasticsearch cluster in its snapshot repository and
+// waits for the snapshot to complete. The ECE-managed found-snapshots repository is used if it is registered,
+// otherwise the cluster must have exactly one registered repository.
+func takeElasticsearchClusterFinalSnapshot(client *ECEClient, clusterID string, snapshotNamePrefix string) error {
+	resp, err := client.GetElasticsearchClusterSnapshotRepositories(clusterID)
+	if err != nil {
+		return err
+	}
 
-		if instance.ServiceRoles != nil {
-			nodeTypeValues := make(map[string]interface{})
-			for _, s := range instance.ServiceRoles {
-				nodeTypeValues[s] = true
-			}
+	var repositories map[string]interface{}
+	err = json.NewDecoder(resp.Body).Decode(&repositories)
+	if err != nil {
+		return err
+	}
 
-			expandElasticsearchNodeTypeFromMap(nodeType, nodeTypeValues)
+	var repository string
+	if _, ok := repositories["found-snapshots"]; ok {
+		repository = "found-snapshots"
+	} else if len(repositories) == 1 {
+		for k := range repositories {
+			repository = k
 		}
+	} else {
+		return fmt.Errorf("%q: elasticsearch cluster has %d snapshot repositories and none is named found-snapshots", clusterID, len(repositories))
+	}
 
-		nodeTypeMap["data"] = nodeType.Data
-		log.Printf("[DEBUG] Flattened node_type.data as: %t\n", nodeTypeMap["data"])
-
-		nodeTypeMap["ingest"] = nodeType.Ingest
-		log.Printf("[DEBUG] Flattened node_type.ingest as: %t\n", nodeTypeMap["ingest"])
-
-		nodeTypeMap["master"] = nodeType.Master
-		log.Printf("[DEBUG] Flattened node_type.master as: %t\n", nodeTypeMap["master"])
+	snapshotName := snapshotNamePrefix + "-" + time.Now().UTC().Format("20060102-150405")
+	log.Printf("[INFO] Taking final snapshot %s of elasticsearch cluster ID: %s in repository: %s\n", snapshotName, clusterID, repository)
 
-		nodeTypeMap["ml"] = nodeType.ML
-		log.Printf("[DEBUG] Flattened node_type.ml as: %t\n", nodeTypeMap["ml"])
+	_, err = client.CreateElasticsearchClusterSnapshot(clusterID, repository, snapshotName)
+	if err != nil {
+		return err
 	}
 
-	return nodeTypeMap
-}
+	err = client.WaitForElasticsearchClusterSnapshot(clusterID, repository, snapshotName)
+	if err != nil {
+		return err
+	}
 
-func flattenElasticsearchSystemSettings(systemSettings ElasticsearchSystemSettings) []map[string]interface{} {
-	systemSettingsMaps := make([]map[string]interface{}, 1)
+	log.Printf("[INFO] Final snapshot %s of elasticsearch cluster ID: %s completed in repository: %s\n", snapshotName, clusterID, repository)
 
-	systemSettingsMap := make(map[string]interface{})
-	systemSettingsMap["use_disk_threshold"] = systemSettings.UseDiskThreshold
+	return nil
+}
 
-	systemSettingsMaps[0] = systemSettingsMap
+// setElasticsearchClusterState starts or stops an Elasticsearch cluster and waits for the cluster to reach the
+// desired state.
+func setElasticsearchClusterState(client *ECEClient, clusterID string, desiredState string) error {
+	var err error
+	if desiredState == "stopped" {
+		log.Printf("[DEBUG] Stopping elasticsearch cluster ID: %s\n", clusterID)
+		_, err = client.ShutdownElasticsearchCluster(clusterID)
+	} else {
+		log.Printf("[DEBUG] Starting elasticsearch cluster ID: %s\n", clusterID)
+		_, err = client.RestartElasticsearchCluster(clusterID, nil)
+	}
 
-	logJSON("Flattened elasticsearch system settings", systemSettingsMaps)
+	if err != nil {
+		return err
+	}
 
-	return systemSettingsMaps
+	return client.WaitForElasticsearchClusterStatus(clusterID, desiredState, false)
 }
 
-func logJSON(context string, m interface{}) {
-	jsonBytes, err := json.MarshalIndent(m, "", "  ")
+// setKibanaClusterState starts or stops a Kibana cluster and waits for the cluster to reach the desired state.
+func setKibanaClusterState(client *ECEClient, kibanaClusterID string, desiredState string) error {
+	var err error
+	if desiredState == "stopped" {
+		log.Printf("[DEBUG] Stopping Kibana cluster ID: %s\n", kibanaClusterID)
+		_, err = client.ShutdownKibanaCluster(kibanaClusterID)
+	} else {
+		log.Printf("[DEBUG] Starting Kibana cluster ID: %s\n", kibanaClusterID)
+		_, err = client.RestartKibanaCluster(kibanaClusterID)
+	}
+
 	if err != nil {
-		log.Printf("[DEBUG] %s: error marshalling value as JSON: %s. %v", context, err, m)
+		return err
 	}
 
-	log.Printf("[DEBUG] %s: %s", context, string(jsonBytes))
+	return client.WaitForKibanaClusterStatus(kibanaClusterID, desiredState, false)
 }
 
 func updateKibanaCluster(client *ECEClient, clusterID string, d *schema.ResourceData, meta interface{}) error {
@@ -839,6 +3133,13 @@ func updateKibanaCluster(client *ECEClient, clusterID string, d *schema.Resource
 		// If the Kibana cluster ID is not empty and a Kibana create request was constructed from
 		// resource inputs, update the existing cluster.
 		if kibanaRequest != nil {
+			// Kibana cannot run a newer version than Elasticsearch, so the Elasticsearch cluster must be
+			// upgraded first.
+			err = validateVersionNotNewerThanElasticsearch("kibana", kibanaRequest.Plan.Kibana.Version, d.Get("plan.0.elasticsearch.0.version").(string))
+			if err != nil {
+				return err
+			}
+
 			// Update the existing Kibana cluster name.
 			metadata := ClusterMetadataSettings{
 				ClusterName: kibanaRequest.ClusterName,
@@ -891,6 +3192,36 @@ func updateKibanaCluster(client *ECEClient, clusterID string, d *schema.Resource
 	return nil
 }
 
+// validateClusterPlanHealth returns an error with the log messages of the failed plan steps when the current plan
+// of the product cluster is not healthy.
+func validateClusterPlanHealth(product string, clusterID string, healthy bool, planAttemptLog []ClusterPlanStepInfo) error {
+	if healthy {
+		return nil
+	}
+
+	var logMessages interface{}
+	failedLogMessages := make([]ClusterPlanStepLogMessageInfo, 0)
+	// Attempt to find the failed step in the plan.
+	for _, stepInfo := range planAttemptLog {
+		if stepInfo.Status != "success" {
+			for _, logMessageInfo := range stepInfo.InfoLog {
+				failedLogMessages = append(failedLogMessages, logMessageInfo)
+			}
+		}
+	}
+
+	logMessages, err := json.MarshalIndent(failedLogMessages, "", " ")
+	if err != nil {
+		log.Printf("[DEBUG] Error marshalling log messages to JSON: %v\n", err)
+
+		logMessages = failedLogMessages
+	} else {
+		logMessages = string(logMessages.([]byte))
+	}
+
+	return fmt.Errorf("%q: %s cluster update failed: %v", clusterID, product, logMessages)
+}
+
 func validateElasticsearchClusterPlanActivity(client *ECEClient, clusterID string) error {
 	resp, err := client.GetElasticsearchClusterPlanActivity(clusterID)
 	if err != nil {
@@ -907,33 +3238,7 @@ func validateElasticsearchClusterPlanActivity(client *ECEClient, clusterID strin
 		return err
 	}
 
-	if !clusterPlansInfo.Current.Healthy {
-		var logMessages interface{}
-		failedLogMessages := make([]ClusterPlanStepLogMessageInfo, 0)
-		// Attempt to find the failed step in the plan.
-		if clusterPlansInfo.Current.PlanAttemptLog != nil {
-			for _, stepInfo := range clusterPlansInfo.Current.PlanAttemptLog {
-				if stepInfo.Status != "success" {
-					for _, logMessageInfo := range stepInfo.InfoLog {
-						failedLogMessages = append(failedLogMessages, logMessageInfo)
-					}
-				}
-			}
-		}
-
-		logMessages, err := json.MarshalIndent(failedLogMessages, "", " ")
-		if err != nil {
-			log.Printf("[DEBUG] Error marshalling log messages to JSON: %v\n", err)
-
-			logMessages = failedLogMessages
-		} else {
-			logMessages = string(logMessages.([]byte))
-		}
-
-		return fmt.Errorf("%q: elasticsearch cluster update failed: %v", clusterID, logMessages)
-	}
-
-	return nil
+	return validateClusterPlanHealth("elasticsearch", clusterID, clusterPlansInfo.Current.Healthy, clusterPlansInfo.Current.PlanAttemptLog)
 }
 
 func validateKibanaClusterPlanActivity(client *ECEClient, clusterID string) error {
@@ -952,31 +3257,213 @@ func validateKibanaClusterPlanActivity(client *ECEClient, clusterID string) erro
 		return err
 	}
 
-	if !clusterPlansInfo.Current.Healthy {
-		var logMessages interface{}
-		failedLogMessages := make([]ClusterPlanStepLogMessageInfo, 0)
-		// Attempt to find the failed step in the plan.
-		if clusterPlansInfo.Current.PlanAttemptLog != nil {
-			for _, stepInfo := range clusterPlansInfo.Current.PlanAttemptLog {
-				if stepInfo.Status != "success" {
-					for _, logMessageInfo := range stepInfo.InfoLog {
-						failedLogMessages = append(failedLogMessages, logMessageInfo)
-					}
-				}
+	return validateClusterPlanHealth("kibana", clusterID, clusterPlansInfo.Current.Healthy, clusterPlansInfo.Current.PlanAttemptLog)
+}
+
+// validateElasticsearchVersionChange confirms that the target version is an available stack version and, when a
+// current version is specified, that the upgrade from the current version is supported. Downgrades are rejected.
+// The upgrade must be in the upgradable_to list of the current stack version. When ECE does not list the upgrade
+// paths, major version upgrades are only allowed from the latest available minor version of the previous major.
+func validateElasticsearchVersionChange(stacks []StackVersionConfig, currentVersion string, targetVersion string) error {
+	var currentStack *StackVersionConfig
+	var targetStack *StackVersionConfig
+	availableVersions := make([]*version.Version, 0)
+
+	for i, stack := range stacks {
+		// The current stack version is still the upgrade path of existing clusters after it is deleted.
+		if stack.Version == currentVersion {
+			currentStack = &stacks[i]
+		}
+
+		if stack.Deleted {
+			continue
+		}
+
+		if stack.Version == targetVersion {
+			targetStack = &stacks[i]
+		}
+
+		if v, err := version.NewVersion(stack.Version); err == nil {
+			availableVersions = append(availableVersions, v)
+		}
+	}
+
+	if targetStack == nil {
+		return fmt.Errorf("elasticsearch version %s is not available in ECE. Install the stack pack for this version before using it", targetVersion)
+	}
+
+	if currentVersion == "" || currentVersion == targetVersion {
+		return nil
+	}
+
+	current, err := version.NewVersion(currentVersion)
+	if err != nil {
+		return fmt.Errorf("current elasticsearch version %s could not be parsed: %v", currentVersion, err)
+	}
+
+	target, err := version.NewVersion(targetVersion)
+	if err != nil {
+		return fmt.Errorf("elasticsearch version %s could not be parsed: %v", targetVersion, err)
+	}
+
+	if target.LessThan(current) {
+		return fmt.Errorf("elasticsearch version cannot be downgraded from %s to %s", currentVersion, targetVersion)
+	}
+
+	if currentStack != nil && len(currentStack.UpgradableTo) > 0 {
+		for _, upgradableTo := range currentStack.UpgradableTo {
+			if upgradableTo == targetVersion {
+				return nil
 			}
 		}
 
-		logMessages, err := json.MarshalIndent(failedLogMessages, "", " ")
-		if err != nil {
-			log.Printf("[DEBUG] Error marshalling log messages to JSON: %v\n", err)
+		return fmt.Errorf("elasticsearch version cannot be upgraded from %s to %s. %s can be upgraded to: %s",
+			currentVersion, targetVersion, currentVersion, strings.Join(currentStack.UpgradableTo, ", "))
+	}
 
-			logMessages = failedLogMessages
-		} else {
-			logMessages = string(logMessages.([]byte))
+	if targetStack.MinUpgradableFrom != "" {
+		minUpgradableFrom, err := version.NewVersion(targetStack.MinUpgradableFrom)
+		if err == nil && current.LessThan(minUpgradableFrom) {
+			return fmt.Errorf("elasticsearch version cannot be upgraded from %s to %s. The minimum version that can be upgraded to %s is %s",
+				currentVersion, targetVersion, targetVersion, targetStack.MinUpgradableFrom)
+		}
+	}
+
+	currentSegments := current.Segments()
+	targetSegments := target.Segments()
+
+	if targetSegments[0] == currentSegments[0] {
+		return nil
+	}
+
+	if targetSegments[0] > currentSegments[0]+1 {
+		return fmt.Errorf("elasticsearch version cannot be upgraded from %s to %s. Major versions must be upgraded one at a time",
+			currentVersion, targetVersion)
+	}
+
+	latestMinor := currentSegments[1]
+	for _, v := range availableVersions {
+		segments := v.Segments()
+		if segments[0] == currentSegments[0] && segments[1] > latestMinor {
+			latestMinor = segments[1]
+		}
+	}
+
+	if latestMinor > currentSegments[1] {
+		return fmt.Errorf("elasticsearch version cannot be upgraded from %s to %s. Upgrade to the latest %d.x minor version (%d.%d) first",
+			currentVersion, targetVersion, currentSegments[0], currentSegments[0], latestMinor)
+	}
+
+	return nil
+}
+
+// validateVersionNotNewerThanElasticsearch confirms that the version of the product, such as Kibana or APM, is not
+// newer than the Elasticsearch version.
+func validateVersionNotNewerThanElasticsearch(product string, productVersion string, elasticsearchVersion string) error {
+	if productVersion == "" || elasticsearchVersion == "" {
+		return nil
+	}
+
+	productSemVer, err := version.NewVersion(productVersion)
+	if err != nil {
+		return fmt.Errorf("%s version %s could not be parsed: %v", product, productVersion, err)
+	}
+
+	elasticsearch, err := version.NewVersion(elasticsearchVersion)
+	if err != nil {
+		return fmt.Errorf("elasticsearch version %s could not be parsed: %v", elasticsearchVersion, err)
+	}
+
+	if productSemVer.GreaterThan(elasticsearch) {
+		return fmt.Errorf("%s version %s cannot be newer than elasticsearch version %s. Upgrade elasticsearch first", product, productVersion, elasticsearchVersion)
+	}
+
+	return nil
+}
+
+// validateElasticsearchClusterTopology confirms that the cluster topology has master-eligible nodes in an odd number
+// of zones, and that each topology element matches an instance configuration and fits in the available zones.
+// Topology elements with no nodes or no memory are disabled and are not validated.
+func validateElasticsearchClusterTopology(clusterTopology []ElasticsearchClusterTopologyElement, instanceConfigurations []InstanceConfiguration, availableZones int) error {
+	errors := make([]string, 0)
+	masterZoneCount := 0
+
+	instanceConfigurationsByID := make(map[string]InstanceConfiguration)
+	for _, c := range instanceConfigurations {
+		instanceConfigurationsByID[c.ID] = c
+	}
+
+	for i, t := range clusterTopology {
+		if t.NodeCountPerZone == 0 || t.MemoryPerNode == 0 {
+			continue
+		}
+
+		key := fmt.Sprintf("plan.0.cluster_topology.%d", i)
+
+		if t.NodeType.Master && t.ZoneCount > masterZoneCount {
+			masterZoneCount = t.ZoneCount
+		}
+
+		if availableZones > 0 && t.ZoneCount > availableZones {
+			errors = append(errors, fmt.Sprintf("%s: zone_count %d is greater than the %d zones available in ECE", key, t.ZoneCount, availableZones))
+		}
+
+		instanceConfiguration, ok := instanceConfigurationsByID[t.InstanceConfigurationID]
+		if !ok {
+			if len(instanceConfigurations) > 0 {
+				errors = append(errors, fmt.Sprintf("%s: instance configuration %q does not exist", key, t.InstanceConfigurationID))
+			}
+			continue
+		}
+
+		sizes := instanceConfiguration.DiscreteSizes
+		if (sizes.Resource == "" || sizes.Resource == "memory") && len(sizes.Sizes) > 0 && !containsInt(sizes.Sizes, t.MemoryPerNode) {
+			errors = append(errors, fmt.Sprintf("%s: memory_per_node %d is not one of the sizes allowed by instance configuration %q: %v",
+				key, t.MemoryPerNode, t.InstanceConfigurationID, sizes.Sizes))
+		}
+
+		if len(instanceConfiguration.NodeTypes) > 0 {
+			for _, nodeType := range elasticsearchNodeTypeRoles(t.NodeType) {
+				if !containsString(instanceConfiguration.NodeTypes, nodeType) {
+					errors = append(errors, fmt.Sprintf("%s: node type %s is not allowed by instance configuration %q: %v",
+						key, nodeType, t.InstanceConfigurationID, instanceConfiguration.NodeTypes))
+				}
+			}
 		}
+	}
+
+	if masterZoneCount == 0 {
+		errors = append(errors, "plan.0.cluster_topology: at least one topology element must have master-eligible nodes")
+	} else if masterZoneCount%2 == 0 {
+		errors = append(errors, fmt.Sprintf("plan.0.cluster_topology: master-eligible nodes are placed in %d zones. Use an odd number of zones so that a master can be elected", masterZoneCount))
+	}
 
-		return fmt.Errorf("%q: kibana cluster update failed: %v", clusterID, logMessages)
+	if len(errors) > 0 {
+		return fmt.Errorf("invalid cluster topology:\n%s", strings.Join(errors, "\n"))
 	}
 
 	return nil
 }
+
+// elasticsearchNodeTypeRoles returns the names of the roles enabled in the specified node type.
+func elasticsearchNodeTypeRoles(nodeType ElasticsearchNodeType) []string {
+	roles := make([]string, 0)
+
+	if nodeType.Data {
+		roles = append(roles, "data")
+	}
+
+	if nodeType.Ingest {
+		roles = append(roles, "ingest")
+	}
+
+	if nodeType.Master {
+		roles = append(roles, "master")
+	}
+
+	if nodeType.ML {
+		roles = append(roles, "ml")
+	}
+
+	return roles
+}
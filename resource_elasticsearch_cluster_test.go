@@ -0,0 +1,1501 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func masterNodeType() ElasticsearchNodeType {
+	return ElasticsearchNodeType{Master: true}
+}
+
+func dataNodeType() ElasticsearchNodeType {
+	return ElasticsearchNodeType{Data: true, Ingest: true}
+}
+
+func instancesWithRoles(count int, roles ...string) []ClusterInstanceInfo {
+	instances := make([]ClusterInstanceInfo, count)
+	for i := range instances {
+		instances[i] = ClusterInstanceInfo{ServiceRoles: roles}
+	}
+
+	return instances
+}
+
+func TestFlattenElasticsearchClusterTopology(t *testing.T) {
+	cases := []struct {
+		name              string
+		clusterInfo       ElasticsearchClusterInfo
+		expectedNodeTypes []ElasticsearchNodeType
+		expectedZones     []int
+	}{
+		{
+			name: "single element in multiple zones",
+			clusterInfo: ElasticsearchClusterInfo{
+				PlanInfo: ElasticsearchClusterPlansInfo{Current: ElasticsearchClusterPlanInfo{Plan: ElasticsearchClusterPlan{
+					ClusterTopology: []ElasticsearchClusterTopologyElement{
+						{MemoryPerNode: 1024, NodeCountPerZone: 1, NodeType: *DefaultElasticsearchNodeType(), ZoneCount: 3},
+					},
+				}}},
+				Topology: ClusterTopologyInfo{Instances: append(
+					instancesWithRoles(2, "master", "data", "ingest"),
+					// Tiebreaker-style instance that does not match the topology element's roles.
+					ClusterInstanceInfo{ServiceRoles: []string{"master"}},
+				)},
+			},
+			expectedNodeTypes: []ElasticsearchNodeType{*DefaultElasticsearchNodeType()},
+			expectedZones:     []int{3},
+		},
+		{
+			name: "dedicated masters with multiple nodes per zone",
+			clusterInfo: ElasticsearchClusterInfo{
+				PlanInfo: ElasticsearchClusterPlansInfo{Current: ElasticsearchClusterPlanInfo{Plan: ElasticsearchClusterPlan{
+					ClusterTopology: []ElasticsearchClusterTopologyElement{
+						{MemoryPerNode: 1024, NodeCountPerZone: 1, NodeType: masterNodeType(), ZoneCount: 3},
+						{MemoryPerNode: 4096, NodeCountPerZone: 2, NodeType: dataNodeType(), ZoneCount: 2},
+					},
+				}}},
+				// Instances are not ordered by topology element, so the first instance is a data node.
+				Topology: ClusterTopologyInfo{Instances: append(
+					instancesWithRoles(4, "data", "ingest"),
+					instancesWithRoles(3, "master")...,
+				)},
+			},
+			expectedNodeTypes: []ElasticsearchNodeType{masterNodeType(), dataNodeType()},
+			expectedZones:     []int{3, 2},
+		},
+		{
+			name: "more topology elements than instances",
+			clusterInfo: ElasticsearchClusterInfo{
+				PlanInfo: ElasticsearchClusterPlansInfo{Current: ElasticsearchClusterPlanInfo{Plan: ElasticsearchClusterPlan{
+					ClusterTopology: []ElasticsearchClusterTopologyElement{
+						{MemoryPerNode: 1024, NodeCountPerZone: 1, NodeType: masterNodeType(), ZoneCount: 1},
+						{MemoryPerNode: 2048, NodeCountPerZone: 1, NodeType: dataNodeType(), ZoneCount: 1},
+						{InstanceConfigurationID: "ml", MemoryPerNode: 0, NodeCountPerZone: 0, NodeType: ElasticsearchNodeType{ML: true}, ZoneCount: 1},
+					},
+				}}},
+				Topology: ClusterTopologyInfo{Instances: instancesWithRoles(1, "master")},
+			},
+			expectedNodeTypes: []ElasticsearchNodeType{masterNodeType(), dataNodeType(), {ML: true}},
+			expectedZones:     []int{1, 1, 1},
+		},
+		{
+			name: "no instances and plan-level zone count",
+			clusterInfo: ElasticsearchClusterInfo{
+				PlanInfo: ElasticsearchClusterPlansInfo{Current: ElasticsearchClusterPlanInfo{Plan: ElasticsearchClusterPlan{
+					ClusterTopology: []ElasticsearchClusterTopologyElement{
+						{MemoryPerNode: 1024, NodeCountPerZone: 1, NodeType: *DefaultElasticsearchNodeType()},
+					},
+					ZoneCount: 2,
+				}}},
+			},
+			expectedNodeTypes: []ElasticsearchNodeType{*DefaultElasticsearchNodeType()},
+			expectedZones:     []int{2},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			topology := flattenElasticsearchClusterTopology(c.clusterInfo.PlanInfo.Current.Plan, 0)
+
+			if len(topology) != len(c.expectedNodeTypes) {
+				t.Fatalf("expected %d topology elements, got %d", len(c.expectedNodeTypes), len(topology))
+			}
+
+			for i, element := range topology {
+				nodeTypeMaps := element["node_type"].([]map[string]interface{})
+				if len(nodeTypeMaps) != 1 {
+					t.Fatalf("element %d: expected 1 node_type, got %d", i, len(nodeTypeMaps))
+				}
+
+				nodeType := ElasticsearchNodeType{}
+				expandElasticsearchNodeTypeFromMap(&nodeType, nodeTypeMaps[0])
+				if nodeType != c.expectedNodeTypes[i] {
+					t.Errorf("element %d: expected node_type %+v, got %+v", i, c.expectedNodeTypes[i], nodeType)
+				}
+
+				if element["zone_count"] != c.expectedZones[i] {
+					t.Errorf("element %d: expected zone_count %d, got %v", i, c.expectedZones[i], element["zone_count"])
+				}
+			}
+		})
+	}
+}
+
+func TestFlattenElasticsearchClusterPlan_setsResourceData(t *testing.T) {
+	clusterInfo := ElasticsearchClusterInfo{
+		PlanInfo: ElasticsearchClusterPlansInfo{Current: ElasticsearchClusterPlanInfo{Plan: ElasticsearchClusterPlan{
+			ClusterTopology: []ElasticsearchClusterTopologyElement{
+				{InstanceConfigurationID: "data.default", MemoryPerNode: 1024, NodeCountPerZone: 1, NodeType: masterNodeType(), ZoneCount: 3},
+				{InstanceConfigurationID: "data.default", MemoryPerNode: 4096, NodeCountPerZone: 2, NodeType: dataNodeType(), ZoneCount: 2},
+			},
+			Elasticsearch: ElasticsearchConfiguration{Version: "7.2.0"},
+		}}},
+		Topology: ClusterTopologyInfo{Instances: instancesWithRoles(7, "data", "ingest")},
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceElasticsearchCluster().Schema, map[string]interface{}{})
+	if err := d.Set("plan", flattenElasticsearchClusterPlan(clusterInfo, 0)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"plan.0.elasticsearch.0.version":                      "7.2.0",
+		"plan.0.cluster_topology.0.node_type.0.master":        true,
+		"plan.0.cluster_topology.0.node_type.0.data":          false,
+		"plan.0.cluster_topology.1.node_type.0.master":        false,
+		"plan.0.cluster_topology.1.node_type.0.data":          true,
+		"plan.0.cluster_topology.1.node_count_per_zone":       2,
+		"plan.0.cluster_topology.1.zone_count":                2,
+		"plan.0.cluster_topology.1.instance_configuration_id": "data.default",
+	}
+
+	for k, v := range expected {
+		if actual := d.Get(k); actual != v {
+			t.Errorf("%s: expected %v, got %v", k, v, actual)
+		}
+	}
+}
+
+func TestFlattenElasticsearchClusterPlan_zoneCount(t *testing.T) {
+	clusterInfo := ElasticsearchClusterInfo{
+		PlanInfo: ElasticsearchClusterPlansInfo{Current: ElasticsearchClusterPlanInfo{Plan: ElasticsearchClusterPlan{
+			ClusterTopology: []ElasticsearchClusterTopologyElement{
+				{MemoryPerNode: 1024, NodeCountPerZone: 1, NodeType: masterNodeType()},
+				{MemoryPerNode: 4096, NodeCountPerZone: 2, NodeType: dataNodeType(), ZoneCount: 2},
+			},
+			ZoneCount: 3,
+		}}},
+	}
+
+	cases := []struct {
+		name              string
+		previousZoneCount int
+		expectedZoneCount int
+		expectedZones     []int
+	}{
+		{"plan zone count not set", 0, 0, []int{3, 2}},
+		{"plan zone count set", 3, 3, []int{0, 2}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			plan := flattenElasticsearchClusterPlan(clusterInfo, c.previousZoneCount)
+
+			if plan[0]["zone_count"] != c.expectedZoneCount {
+				t.Errorf("expected plan zone_count %d, got %v", c.expectedZoneCount, plan[0]["zone_count"])
+			}
+
+			for i, element := range plan[0]["cluster_topology"].([]map[string]interface{}) {
+				if element["zone_count"] != c.expectedZones[i] {
+					t.Errorf("element %d: expected zone_count %d, got %v", i, c.expectedZones[i], element["zone_count"])
+				}
+			}
+		})
+	}
+}
+
+func TestExpandElasticsearchClusterPlan_planZoneCountRemoved(t *testing.T) {
+	plan := func(zoneCount int) []interface{} {
+		planMap := map[string]interface{}{
+			"cluster_topology": []interface{}{
+				map[string]interface{}{
+					"node_type": []interface{}{
+						map[string]interface{}{"data": true},
+					},
+				},
+			},
+			"elasticsearch": []interface{}{
+				map[string]interface{}{"version": "7.2.0"},
+			},
+		}
+		if zoneCount > 0 {
+			planMap["zone_count"] = zoneCount
+		}
+
+		return []interface{}{planMap}
+	}
+
+	// The topology element uses the plan zone count, so it has no zone count of its own in the state.
+	state := map[string]interface{}{"plan": plan(3)}
+	d := testResourceDataWithState(t, resourceElasticsearchCluster().Schema, state, map[string]interface{}{"plan": plan(0)})
+
+	if !d.HasChange("plan.0.zone_count") {
+		t.Errorf("expected removing the plan zone_count to be a change")
+	}
+
+	clusterPlan, err := expandElasticsearchClusterPlan(d, &ECEClient{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if zoneCount := clusterPlan.ClusterTopology[0].ZoneCount; zoneCount != DefaultElasticsearchClusterTopologyElement().ZoneCount {
+		t.Errorf("expected the topology element to use the default zone count, got %d", zoneCount)
+	}
+}
+
+func TestSuppressTopologyDefaultDiff(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceElasticsearchCluster().Schema, map[string]interface{}{
+		"plan": []interface{}{
+			map[string]interface{}{
+				"zone_count": 2,
+				"elasticsearch": []interface{}{
+					map[string]interface{}{
+						"version":            "7.2.0",
+						"user_settings_json": `{"action.auto_create_index":false}`,
+					},
+				},
+			},
+		},
+	})
+
+	cases := []struct {
+		name     string
+		key      string
+		old      string
+		new      string
+		suppress func(k, old, new string, d *schema.ResourceData) bool
+		expected bool
+	}{
+		{"zone count from plan", "plan.0.cluster_topology.0.zone_count", "2", "0", suppressTopologyZoneCountDiff, true},
+		{"zone count changed from plan", "plan.0.cluster_topology.0.zone_count", "3", "0", suppressTopologyZoneCountDiff, false},
+		{"zone count set in element", "plan.0.cluster_topology.0.zone_count", "2", "3", suppressTopologyZoneCountDiff, false},
+		{"settings from plan", "plan.0.cluster_topology.0.elasticsearch.0.user_settings_json", "", `{"action.auto_create_index": false}`, suppressTopologyElasticsearchDiff, true},
+		{"settings returned in element", "plan.0.cluster_topology.0.elasticsearch.0.user_settings_json", `{"action.auto_create_index":false}`, "", suppressTopologyElasticsearchDiff, true},
+		{"settings overridden in element", "plan.0.cluster_topology.0.elasticsearch.0.user_settings_json", "", `{"action.auto_create_index":true}`, suppressTopologyElasticsearchDiff, false},
+		{"memory default", "plan.0.cluster_topology.0.memory_per_node", "1024", "0", suppressTopologyDefaultDiff("1024"), true},
+		{"memory changed from default", "plan.0.cluster_topology.0.memory_per_node", "2048", "0", suppressTopologyDefaultDiff("1024"), false},
+		{"instance configuration default", "plan.0.cluster_topology.0.instance_configuration_id", "data.default", "", suppressTopologyDefaultDiff("data.default"), true},
+		{"instance configuration set", "plan.0.cluster_topology.0.instance_configuration_id", "data.default", "data.highio", suppressTopologyDefaultDiff("data.default"), false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if actual := c.suppress(c.key, c.old, c.new, d); actual != c.expected {
+				t.Errorf("expected %t, got %t", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSuppressTopologyDefaultDiff_deploymentTemplate(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceElasticsearchCluster().Schema, map[string]interface{}{
+		"deployment_template_id": "default",
+		"plan": []interface{}{
+			map[string]interface{}{
+				"elasticsearch": []interface{}{
+					map[string]interface{}{
+						"version": "7.2.0",
+					},
+				},
+			},
+		},
+	})
+
+	cases := []struct {
+		name     string
+		key      string
+		old      string
+		new      string
+		suppress func(k, old, new string, d *schema.ResourceData) bool
+		expected bool
+	}{
+		{"memory from template", "plan.0.cluster_topology.0.memory_per_node", "4096", "0", suppressTopologyDefaultDiff("1024"), true},
+		{"memory set", "plan.0.cluster_topology.0.memory_per_node", "4096", "1024", suppressTopologyDefaultDiff("1024"), false},
+		{"zone count from template", "plan.0.cluster_topology.0.zone_count", "3", "0", suppressTopologyZoneCountDiff, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if actual := c.suppress(c.key, c.old, c.new, d); actual != c.expected {
+				t.Errorf("expected %t, got %t", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestElasticsearchUserSettingsRemoved_deploymentTemplate(t *testing.T) {
+	plan := func(elasticsearch map[string]interface{}) []interface{} {
+		elasticsearch["version"] = "7.2.0"
+
+		return []interface{}{
+			map[string]interface{}{
+				"elasticsearch": []interface{}{elasticsearch},
+			},
+		}
+	}
+
+	state := map[string]interface{}{
+		"deployment_template_id": "default",
+		"plan":                   plan(map[string]interface{}{"user_settings_yaml": "a: b", "user_settings_json": `{"a":"b"}`}),
+	}
+
+	cases := []struct {
+		name     string
+		plan     []interface{}
+		key      string
+		expected bool
+	}{
+		{"user_settings_yaml removed", plan(map[string]interface{}{"user_settings_json": `{"a":"b"}`}), "plan.0.elasticsearch.0.user_settings_yaml", true},
+		{"user_settings_json removed", plan(map[string]interface{}{"user_settings_yaml": "a: b"}), "plan.0.elasticsearch.0.user_settings_json", true},
+		{"user_settings_json reformatted", plan(map[string]interface{}{"user_settings_yaml": "a: b", "user_settings_json": `{"a": "b"}`}), "plan.0.elasticsearch.0.user_settings_json", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := testResourceDataWithState(t, resourceElasticsearchCluster().Schema, state, map[string]interface{}{
+				"deployment_template_id": "default",
+				"plan":                   c.plan,
+			})
+
+			if actual := d.HasChange(c.key); actual != c.expected {
+				t.Errorf("expected change %t, got %t", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFlattenElasticsearchTemplateUserSettings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		fmt.Fprint(w, `{
+			"id": "default",
+			"cluster_template": {
+				"plan": {
+					"elasticsearch": {
+						"user_settings_yaml": "action.auto_create_index: false",
+						"user_settings_json": {"script.painless.regex.enabled": true}
+					}
+				}
+			}
+		}`)
+	}))
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+	d := schema.TestResourceDataRaw(t, resourceElasticsearchCluster().Schema, map[string]interface{}{
+		"deployment_template_id": "default",
+		"plan": []interface{}{
+			map[string]interface{}{
+				"elasticsearch": []interface{}{
+					map[string]interface{}{
+						"version":            "7.2.0",
+						"user_settings_json": `{"script.painless.regex.enabled":true}`,
+					},
+				},
+			},
+		},
+	})
+
+	elasticsearchMaps := flattenElasticsearchConfiguration(ElasticsearchConfiguration{
+		UserSettingsJSON:         map[string]interface{}{"script.painless.regex.enabled": true},
+		UserSettingsOverrideYAML: "action.destructive_requires_name: true",
+		UserSettingsYAML:         "action.auto_create_index: false",
+		Version:                  "7.2.0",
+	})
+
+	err := flattenElasticsearchTemplateUserSettings(d, client, elasticsearchMaps)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"user_settings_json":          `{"script.painless.regex.enabled":true}`,
+		"user_settings_override_json": "",
+		"user_settings_override_yaml": "action.destructive_requires_name: true",
+		"user_settings_yaml":          "",
+	}
+
+	for k, v := range expected {
+		if actual := elasticsearchMaps[0][k]; actual != v {
+			t.Errorf("%s: expected %q, got %q", k, v, actual)
+		}
+	}
+}
+
+func TestExpandElasticsearchClusterPlan_deploymentTemplate(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", jsonContentType)
+		fmt.Fprint(w, `{
+			"id": "default",
+			"cluster_template": {
+				"plan": {
+					"cluster_topology": [{"instance_configuration_id": "data.highio", "memory_per_node": 4096, "zone_count": 2}],
+					"elasticsearch": {
+						"system_settings": {"use_disk_threshold": false},
+						"user_settings_yaml": "action.auto_create_index: false",
+						"user_settings_json": {"script.painless.regex.enabled": true}
+					}
+				}
+			}
+		}`)
+	}))
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+	d := schema.TestResourceDataRaw(t, resourceElasticsearchCluster().Schema, map[string]interface{}{
+		"deployment_template_id": "default",
+		"plan": []interface{}{
+			map[string]interface{}{
+				"cluster_topology": []interface{}{
+					map[string]interface{}{
+						"node_type": []interface{}{
+							map[string]interface{}{"data": true},
+						},
+					},
+				},
+				"elasticsearch": []interface{}{
+					map[string]interface{}{
+						"version":            "7.2.0",
+						"user_settings_json": `{"action.destructive_requires_name":true}`,
+					},
+				},
+			},
+		},
+	})
+
+	for i := 0; i < 2; i++ {
+		clusterPlan, err := expandElasticsearchClusterPlan(d, client)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expectedElement := ElasticsearchClusterTopologyElement{
+			InstanceConfigurationID: "data.highio",
+			MemoryPerNode:           4096,
+			NodeCountPerZone:        1,
+			ZoneCount:               2,
+		}
+		if element := clusterPlan.ClusterTopology[0]; element.InstanceConfigurationID != expectedElement.InstanceConfigurationID ||
+			element.MemoryPerNode != expectedElement.MemoryPerNode ||
+			element.NodeCountPerZone != expectedElement.NodeCountPerZone ||
+			element.ZoneCount != expectedElement.ZoneCount {
+			t.Errorf("expected topology element %+v, got %+v", expectedElement, element)
+		}
+
+		expectedElasticsearch := ElasticsearchConfiguration{
+			SystemSettings:   &ElasticsearchSystemSettings{UseDiskThreshold: false},
+			UserSettingsJSON: map[string]interface{}{"action.destructive_requires_name": true},
+			UserSettingsYAML: "action.auto_create_index: false",
+			Version:          "7.2.0",
+		}
+		if !reflect.DeepEqual(clusterPlan.Elasticsearch, expectedElasticsearch) {
+			t.Errorf("expected elasticsearch settings %+v, got %+v", expectedElasticsearch, clusterPlan.Elasticsearch)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("expected the deployment template to be read once, got %d requests", requests)
+	}
+}
+
+func TestExpandElasticsearchClusterPlan_defaults(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceElasticsearchCluster().Schema, map[string]interface{}{
+		"plan": []interface{}{
+			map[string]interface{}{
+				"cluster_topology": []interface{}{
+					map[string]interface{}{
+						"node_type": []interface{}{
+							map[string]interface{}{"data": true},
+						},
+					},
+				},
+				"elasticsearch": []interface{}{
+					map[string]interface{}{
+						"version": "7.2.0",
+					},
+				},
+			},
+		},
+	})
+
+	clusterPlan, err := expandElasticsearchClusterPlan(d, &ECEClient{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	element := clusterPlan.ClusterTopology[0]
+	if element.InstanceConfigurationID != "data.default" || element.MemoryPerNode != 1024 ||
+		element.NodeCountPerZone != 1 || element.ZoneCount != 1 {
+		t.Errorf("expected default topology element, got %+v", element)
+	}
+
+	if !clusterPlan.Elasticsearch.SystemSettings.UseDiskThreshold {
+		t.Errorf("expected use_disk_threshold to default to true")
+	}
+}
+
+func TestValidateElasticsearchVersionChange(t *testing.T) {
+	stacks := []StackVersionConfig{
+		{Version: "5.6.16"},
+		{Version: "6.7.0", MinUpgradableFrom: "5.6.0"},
+		{Version: "6.8.0", MinUpgradableFrom: "5.6.0"},
+		{Version: "7.1.0", MinUpgradableFrom: "6.0.0"},
+		{Version: "7.2.0", MinUpgradableFrom: "6.0.0"},
+		{Version: "7.3.0", MinUpgradableFrom: "6.8.0", Deleted: true},
+	}
+
+	cases := []struct {
+		name           string
+		currentVersion string
+		targetVersion  string
+		expectedError  string
+	}{
+		{"new cluster", "", "7.2.0", ""},
+		{"same version", "7.2.0", "7.2.0", ""},
+		{"minor upgrade", "7.1.0", "7.2.0", ""},
+		{"major upgrade from latest minor", "6.8.0", "7.2.0", ""},
+		{"unknown version", "7.2.0", "7.4.0", "is not available in ECE"},
+		{"deleted version", "7.2.0", "7.3.0", "is not available in ECE"},
+		{"downgrade", "7.2.0", "7.1.0", "cannot be downgraded"},
+		{"major skip", "5.6.16", "7.2.0", "minimum version that can be upgraded"},
+		{"major upgrade from older minor", "6.7.0", "7.2.0", "Upgrade to the latest 6.x minor version (6.8) first"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateElasticsearchVersionChange(stacks, c.currentVersion, c.targetVersion)
+			if c.expectedError == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+				t.Errorf("expected error containing %q, got %v", c.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestValidateElasticsearchVersionChange_upgradableTo(t *testing.T) {
+	stacks := []StackVersionConfig{
+		{Version: "6.5.0"},
+		{Version: "6.7.0", UpgradableTo: []string{"6.8.0", "7.2.0"}},
+		{Version: "6.8.0", MinUpgradableFrom: "5.6.0"},
+		{Version: "7.1.0", MinUpgradableFrom: "6.0.0", UpgradableTo: []string{"7.3.0"}},
+		{Version: "7.2.0", MinUpgradableFrom: "6.0.0"},
+		{Version: "7.3.0", MinUpgradableFrom: "6.8.0"},
+		{Version: "7.4.0", Deleted: true, UpgradableTo: []string{"7.5.0"}},
+		{Version: "7.5.0", MinUpgradableFrom: "7.0.0"},
+	}
+
+	cases := []struct {
+		name           string
+		currentVersion string
+		targetVersion  string
+		expectedError  string
+	}{
+		{"major upgrade listed for the current version", "6.7.0", "7.2.0", ""},
+		{"minor upgrade listed for the current version", "7.1.0", "7.3.0", ""},
+		{"minor upgrade not listed for the current version", "7.1.0", "7.2.0", "7.1.0 can be upgraded to: 7.3.0"},
+		{"upgrade listed for a deleted current version", "7.4.0", "7.5.0", ""},
+		{"downgrade listed for the current version", "7.4.0", "7.3.0", "cannot be downgraded"},
+		{"fallback without upgradable_to", "6.8.0", "7.2.0", ""},
+		{"fallback without upgradable_to rejects a major upgrade from an older minor", "6.5.0", "7.2.0", "Upgrade to the latest 6.x minor version (6.8) first"},
+		{"fallback for a current version that is not installed", "6.6.0", "7.2.0", "Upgrade to the latest 6.x minor version (6.8) first"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateElasticsearchVersionChange(stacks, c.currentVersion, c.targetVersion)
+			if c.expectedError == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+				t.Errorf("expected error containing %q, got %v", c.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestValidateElasticsearchVersionChange_majorSkipWithoutMinimum(t *testing.T) {
+	stacks := []StackVersionConfig{
+		{Version: "5.6.16"},
+		{Version: "7.2.0"},
+	}
+
+	err := validateElasticsearchVersionChange(stacks, "5.6.16", "7.2.0")
+	if err == nil || !strings.Contains(err.Error(), "Major versions must be upgraded one at a time") {
+		t.Errorf("expected major version skip error, got %v", err)
+	}
+}
+
+func TestExpandKibanaCreateRequest_version(t *testing.T) {
+	cases := []struct {
+		name            string
+		kibana          []interface{}
+		expectedVersion string
+	}{
+		{"empty kibana block", []interface{}{nil}, "7.2.0"},
+		{"upgrade with elasticsearch", []interface{}{
+			map[string]interface{}{
+				"plan": []interface{}{
+					map[string]interface{}{
+						"kibana": []interface{}{
+							map[string]interface{}{"version": "7.1.0"},
+						},
+					},
+				},
+			},
+		}, "7.2.0"},
+		{"upgrade_with_elasticsearch false", []interface{}{
+			map[string]interface{}{
+				"upgrade_with_elasticsearch": false,
+				"plan": []interface{}{
+					map[string]interface{}{
+						"kibana": []interface{}{
+							map[string]interface{}{"version": "7.1.0"},
+						},
+					},
+				},
+			},
+		}, "7.1.0"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceElasticsearchCluster().Schema, map[string]interface{}{
+				"kibana": c.kibana,
+				"plan": []interface{}{
+					map[string]interface{}{
+						"elasticsearch": []interface{}{
+							map[string]interface{}{"version": "7.2.0"},
+						},
+					},
+				},
+			})
+
+			kibanaRequest, err := expandKibanaCreateRequest(d, &ECEClient{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if kibanaRequest.Plan.Kibana.Version != c.expectedVersion {
+				t.Errorf("expected Kibana version %s, got %s", c.expectedVersion, kibanaRequest.Plan.Kibana.Version)
+			}
+		})
+	}
+}
+
+func TestValidateElasticsearchClusterTopology(t *testing.T) {
+	instanceConfigurations := []InstanceConfiguration{
+		{
+			ID:            "data.default",
+			DiscreteSizes: DiscreteSizes{Resource: "memory", Sizes: []int{1024, 2048, 4096}},
+			NodeTypes:     []string{"data", "ingest", "master"},
+		},
+	}
+
+	masterDataElement := func(zoneCount int) ElasticsearchClusterTopologyElement {
+		return ElasticsearchClusterTopologyElement{
+			InstanceConfigurationID: "data.default",
+			MemoryPerNode:           1024,
+			NodeCountPerZone:        1,
+			NodeType:                ElasticsearchNodeType{Data: true, Master: true},
+			ZoneCount:               zoneCount,
+		}
+	}
+
+	cases := []struct {
+		name            string
+		clusterTopology []ElasticsearchClusterTopologyElement
+		availableZones  int
+		expectedError   string
+	}{
+		{"valid topology", []ElasticsearchClusterTopologyElement{masterDataElement(3)}, 3, ""},
+		{"even master zones", []ElasticsearchClusterTopologyElement{masterDataElement(2)}, 3, "Use an odd number of zones"},
+		{"no master nodes", []ElasticsearchClusterTopologyElement{
+			{InstanceConfigurationID: "data.default", MemoryPerNode: 1024, NodeCountPerZone: 1, NodeType: dataNodeType(), ZoneCount: 1},
+		}, 3, "at least one topology element must have master-eligible nodes"},
+		{"too many zones", []ElasticsearchClusterTopologyElement{masterDataElement(5)}, 3, "zone_count 5 is greater than the 3 zones available in ECE"},
+		{"unknown instance configuration", []ElasticsearchClusterTopologyElement{
+			{InstanceConfigurationID: "data.unknown", MemoryPerNode: 1024, NodeCountPerZone: 1, NodeType: masterNodeType(), ZoneCount: 1},
+		}, 3, `instance configuration "data.unknown" does not exist`},
+		{"memory size not allowed", []ElasticsearchClusterTopologyElement{
+			{InstanceConfigurationID: "data.default", MemoryPerNode: 3072, NodeCountPerZone: 1, NodeType: masterNodeType(), ZoneCount: 1},
+		}, 3, "memory_per_node 3072 is not one of the sizes allowed"},
+		{"node type not allowed", []ElasticsearchClusterTopologyElement{
+			{InstanceConfigurationID: "data.default", MemoryPerNode: 1024, NodeCountPerZone: 1, NodeType: ElasticsearchNodeType{Master: true, ML: true}, ZoneCount: 1},
+		}, 3, "node type ml is not allowed"},
+		{"zones unknown", []ElasticsearchClusterTopologyElement{masterDataElement(5)}, 0, ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateElasticsearchClusterTopology(c.clusterTopology, instanceConfigurations, c.availableZones)
+			if c.expectedError == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+				t.Errorf("expected error containing %q, got %v", c.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestValidateElasticsearchClusterPlanTopology_forbidden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"errors": [{"code": "root.unauthorized.rbac"}]}`)
+	}))
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+	clusterPlan := &ElasticsearchClusterPlan{
+		ClusterTopology: []ElasticsearchClusterTopologyElement{
+			{InstanceConfigurationID: "data.unknown", MemoryPerNode: 3072, NodeCountPerZone: 1, NodeType: masterNodeType(), ZoneCount: 5},
+		},
+	}
+
+	err := validateElasticsearchClusterPlanTopology(client, clusterPlan)
+	if err != nil {
+		t.Errorf("expected instance configuration and zone checks to be skipped, got %v", err)
+	}
+
+	clusterPlan.ClusterTopology[0].ZoneCount = 2
+	err = validateElasticsearchClusterPlanTopology(client, clusterPlan)
+	if err == nil || !strings.Contains(err.Error(), "Use an odd number of zones") {
+		t.Errorf("expected master zone count error, got %v", err)
+	}
+}
+
+func TestParseElasticsearchClusterImportID(t *testing.T) {
+	cases := []struct {
+		importID                string
+		expectedClusterRef      string
+		expectedKibanaClusterID string
+	}{
+		{"1a2b3c", "1a2b3c", ""},
+		{"1a2b3c:4d5e6f", "1a2b3c", "4d5e6f"},
+		{"my-cluster", "my-cluster", ""},
+		{"my-cluster:4d5e6f", "my-cluster", "4d5e6f"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.importID, func(t *testing.T) {
+			clusterRef, kibanaClusterID := parseElasticsearchClusterImportID(c.importID)
+			if clusterRef != c.expectedClusterRef || kibanaClusterID != c.expectedKibanaClusterID {
+				t.Errorf("expected (%q, %q), got (%q, %q)", c.expectedClusterRef, c.expectedKibanaClusterID, clusterRef, kibanaClusterID)
+			}
+		})
+	}
+}
+
+func TestImportedKibanaClusterID(t *testing.T) {
+	clusterInfo := &ElasticsearchClusterInfo{
+		ClusterID: "1a2b3c",
+		AssociatedKibanaClusters: []KibanaSubClusterInfo{
+			{Enabled: true, KibanaID: "4d5e6f"},
+			{Enabled: true, KibanaID: "7a8b9c"},
+		},
+	}
+
+	cases := []struct {
+		name            string
+		clusterInfo     *ElasticsearchClusterInfo
+		kibanaClusterID string
+		expected        string
+		expectedError   string
+	}{
+		{"first associated cluster", clusterInfo, "", "4d5e6f", ""},
+		{"specified cluster", clusterInfo, "7a8b9c", "7a8b9c", ""},
+		{"cluster not associated", clusterInfo, "0d0e0f", "", "is not associated with elasticsearch cluster"},
+		{"no associated clusters", &ElasticsearchClusterInfo{ClusterID: "1a2b3c"}, "", "", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := importedKibanaClusterID(c.clusterInfo, c.kibanaClusterID)
+			if c.expectedError == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+				t.Errorf("expected error containing %q, got %v", c.expectedError, err)
+			}
+
+			if actual != c.expected {
+				t.Errorf("expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFindElasticsearchCluster(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		switch r.URL.Path {
+		case elasticsearchResource:
+			fmt.Fprint(w, `{"elasticsearch_clusters": [
+				{"cluster_id": "1a2b3c", "cluster_name": "logging"},
+				{"cluster_id": "4d5e6f", "cluster_name": "metrics"},
+				{"cluster_id": "7a8b9c", "cluster_name": "metrics"}
+			]}`)
+		case elasticsearchResource + "/1a2b3c":
+			fmt.Fprint(w, `{"cluster_id": "1a2b3c", "cluster_name": "logging"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors": [{"code": "clusters.cluster_not_found"}]}`)
+		}
+	}))
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+
+	cases := []struct {
+		name          string
+		clusterRef    string
+		expectedID    string
+		expectedError string
+	}{
+		{"by ID", "1a2b3c", "1a2b3c", ""},
+		{"by name", "logging", "1a2b3c", ""},
+		{"name not found", "search", "", "no elasticsearch cluster found with this ID or name"},
+		{"name not unique", "metrics", "", "multiple elasticsearch clusters found with this name"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clusterInfo, err := findElasticsearchCluster(client, c.clusterRef)
+			if c.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+					t.Errorf("expected error containing %q, got %v", c.expectedError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if clusterInfo.ClusterID != c.expectedID {
+				t.Errorf("expected cluster ID %q, got %q", c.expectedID, clusterInfo.ClusterID)
+			}
+		})
+	}
+}
+
+func TestSetSchemaDefaults(t *testing.T) {
+	d := resourceElasticsearchCluster().Data(nil)
+	d.SetId("1a2b3c")
+
+	err := setSchemaDefaults(d, resourceElasticsearchCluster().Schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"desired_state":                 "started",
+		"deletion_protection":           false,
+		"final_snapshot_name_prefix":    "final-snapshot",
+		"final_snapshot_ignore_failure": false,
+	}
+
+	state := d.State()
+	for k, v := range expected {
+		if actual := state.Attributes[k]; actual != fmt.Sprint(v) {
+			t.Errorf("expected %s to be %v, got %v", k, v, actual)
+		}
+	}
+}
+
+func TestExpandElasticsearchClusterRemoteClusters(t *testing.T) {
+	remoteClusters := []RemoteClusterInfo{
+		{Alias: "logging", ElasticsearchID: "1a2b3c", SkipUnavailable: false},
+		{Alias: "metrics", ElasticsearchID: "4d5e6f", SkipUnavailable: true},
+	}
+
+	remoteClustersList := make([]interface{}, 0)
+	for _, m := range flattenElasticsearchClusterRemoteClusters(remoteClusters) {
+		remoteClustersList = append(remoteClustersList, m)
+	}
+
+	actual := expandElasticsearchClusterRemoteClusters(remoteClustersList)
+	if !reflect.DeepEqual(actual, remoteClusters) {
+		t.Errorf("expected %+v, got %+v", remoteClusters, actual)
+	}
+}
+
+func TestReadElasticsearchClusterRemoteClusters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		switch r.URL.Path {
+		case elasticsearchResource + "/0a0b0c/ccs/settings":
+			fmt.Fprint(w, `{"remotes": [
+				{"alias": "search", "elasticsearch_id": "7a8b9c", "skip_unavailable": false},
+				{"alias": "metrics", "elasticsearch_id": "4d5e6f", "skip_unavailable": true},
+				{"alias": "logging", "elasticsearch_id": "1a2b3c", "skip_unavailable": false}
+			]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors": [{"code": "clusters.cluster_not_found"}]}`)
+		}
+	}))
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+
+	t.Run("keeps state order", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, resourceElasticsearchCluster().Schema, map[string]interface{}{
+			"remote_clusters": []interface{}{
+				map[string]interface{}{"alias": "logging", "target_cluster_id": "1a2b3c"},
+				map[string]interface{}{"alias": "metrics", "target_cluster_id": "4d5e6f", "skip_unavailable": true},
+			},
+		})
+
+		err := readElasticsearchClusterRemoteClusters(client, "0a0b0c", d)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := []string{"logging", "metrics", "search"}
+		remoteClusters := d.Get("remote_clusters").([]interface{})
+		if len(remoteClusters) != len(expected) {
+			t.Fatalf("expected %d remote clusters, got %d", len(expected), len(remoteClusters))
+		}
+
+		for i, alias := range expected {
+			if actual := remoteClusters[i].(map[string]interface{})["alias"]; actual != alias {
+				t.Errorf("expected remote cluster %d to be %s, got %v", i, alias, actual)
+			}
+		}
+	})
+
+	t.Run("not a cross-cluster search cluster", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, resourceElasticsearchCluster().Schema, map[string]interface{}{})
+
+		err := readElasticsearchClusterRemoteClusters(client, "1a2b3c", d)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if remoteClusters := d.Get("remote_clusters").([]interface{}); len(remoteClusters) != 0 {
+			t.Errorf("expected no remote clusters, got %v", remoteClusters)
+		}
+	})
+}
+
+// testResourceDataWithState returns the resource data for a change from the specified state to the raw configuration.
+func testResourceDataWithState(t *testing.T, schemaMap map[string]*schema.Schema, state map[string]interface{}, raw map[string]interface{}) *schema.ResourceData {
+	t.Helper()
+
+	stateData := schema.TestResourceDataRaw(t, schemaMap, state)
+	stateData.SetId("1a2b3c")
+	instanceState := stateData.State()
+
+	c, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	diff, err := schema.InternalMap(schemaMap).Diff(instanceState, terraform.NewResourceConfig(c), nil, nil, true)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	d, err := schema.InternalMap(schemaMap).Data(instanceState, diff)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return d
+}
+
+func TestKibanaClusterChanged(t *testing.T) {
+	kibana := func(clusterName string, desiredState string) []interface{} {
+		return []interface{}{
+			map[string]interface{}{
+				"cluster_name":  clusterName,
+				"desired_state": desiredState,
+			},
+		}
+	}
+
+	cases := []struct {
+		name      string
+		oldKibana []interface{}
+		newKibana []interface{}
+		expected  bool
+	}{
+		{"unchanged", kibana("kibana", "started"), kibana("kibana", "started"), false},
+		{"desired state changed", kibana("kibana", "started"), kibana("kibana", "stopped"), false},
+		{"cluster name changed", kibana("kibana", "started"), kibana("dashboards", "started"), true},
+		{"cluster name and desired state changed", kibana("kibana", "started"), kibana("dashboards", "stopped"), true},
+		{"kibana added", nil, kibana("kibana", "started"), true},
+		{"kibana removed", kibana("kibana", "started"), nil, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			state := map[string]interface{}{}
+			if c.oldKibana != nil {
+				state["kibana"] = c.oldKibana
+			}
+
+			raw := map[string]interface{}{}
+			if c.newKibana != nil {
+				raw["kibana"] = c.newKibana
+			}
+
+			d := testResourceDataWithState(t, resourceElasticsearchCluster().Schema, state, raw)
+			if actual := kibanaClusterChanged(d); actual != c.expected {
+				t.Errorf("expected %t, got %t", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFlattenDesiredState(t *testing.T) {
+	cases := []struct {
+		status   string
+		expected string
+	}{
+		{"started", "started"},
+		{"initializing", "started"},
+		{"reconfiguring", "started"},
+		{"stopping", "stopped"},
+		{"stopped", "stopped"},
+		{"", "started"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.status, func(t *testing.T) {
+			if actual := flattenDesiredState(c.status); actual != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestResourceElasticsearchClusterDelete_deletionProtection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", jsonContentType)
+		fmt.Fprint(w, `{"cluster_id": "1a2b3c", "cluster_name": "logging", "status": "started"}`)
+	}))
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+	d := schema.TestResourceDataRaw(t, resourceElasticsearchCluster().Schema, map[string]interface{}{
+		"deletion_protection": true,
+	})
+	d.SetId("1a2b3c")
+
+	err := resourceElasticsearchClusterDelete(d, client)
+	if err == nil || !strings.Contains(err.Error(), "deletion_protection is enabled") {
+		t.Errorf("expected deletion protection error, got %v", err)
+	}
+}
+
+// Deletion protection only guards deletion, which is sufficient as long as no attribute replaces the cluster.
+func TestResourceElasticsearchClusterDelete_finalSnapshotStoppedCluster(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		if r.Method == "GET" && r.URL.Path == elasticsearchResource+"/1a2b3c" {
+			fmt.Fprint(w, `{"cluster_id": "1a2b3c", "cluster_name": "logging", "status": "stopped"}`)
+			return
+		}
+
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL, Timeout: 30}
+
+	cases := []struct {
+		name             string
+		ignoreFailure    bool
+		expectedError    string
+		expectedRequests []string
+	}{
+		{"snapshot failure not ignored", false, "the cluster is stopped", nil},
+		{"snapshot failure ignored", true, "", []string{"DELETE " + elasticsearchResource + "/1a2b3c"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			requests = nil
+
+			d := schema.TestResourceDataRaw(t, resourceElasticsearchCluster().Schema, map[string]interface{}{
+				"final_snapshot":                true,
+				"final_snapshot_ignore_failure": c.ignoreFailure,
+			})
+			d.SetId("1a2b3c")
+
+			err := resourceElasticsearchClusterDelete(d, client)
+			if c.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+				t.Errorf("expected error containing %q, got %v", c.expectedError, err)
+			}
+
+			if !reflect.DeepEqual(requests, c.expectedRequests) {
+				t.Errorf("expected requests %v, got %v", c.expectedRequests, requests)
+			}
+		})
+	}
+}
+
+func TestResourceElasticsearchCluster_noForceNew(t *testing.T) {
+	for k, v := range resourceElasticsearchCluster().Schema {
+		if v.ForceNew {
+			t.Errorf("%s: attribute replaces the cluster, which is not covered by deletion_protection", k)
+		}
+	}
+}
+
+func TestDeleteElasticsearchClusterAssociations(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deleted = append(deleted, r.URL.Path)
+			fmt.Fprint(w, `{}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", jsonContentType)
+		fmt.Fprint(w, `{"status": "stopped"}`)
+	}))
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL, Timeout: 1}
+	clusterInfo := ElasticsearchClusterInfo{
+		ClusterID: "1a2b3c",
+		AssociatedKibanaClusters: []KibanaSubClusterInfo{
+			{Enabled: true, KibanaID: "4d5e6f"},
+			{Enabled: true, KibanaID: "7a8b9c"},
+		},
+		AssociatedApmClusters: []ApmSubInfo{
+			{Enabled: true, ApmID: "0d0e0f"},
+		},
+	}
+
+	cases := []struct {
+		name                     string
+		kibanaClusterID          string
+		deleteAssociatedClusters bool
+		expected                 []string
+	}{
+		{"managed Kibana cluster", "4d5e6f", false, []string{kibanaResource + "/4d5e6f"}},
+		{"no managed Kibana cluster", "", false, nil},
+		{"all associated clusters", "4d5e6f", true, []string{kibanaResource + "/4d5e6f", kibanaResource + "/7a8b9c", apmResource + "/0d0e0f"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			deleted = nil
+
+			err := deleteElasticsearchClusterAssociations(client, clusterInfo, c.kibanaClusterID, c.deleteAssociatedClusters)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(deleted, c.expected) {
+				t.Errorf("expected deleted clusters %v, got %v", c.expected, deleted)
+			}
+		})
+	}
+}
+
+func TestFlattenElasticsearchClusterMonitoring(t *testing.T) {
+	monitoring := func(targetClusterID string, logs bool, metrics bool) []interface{} {
+		return []interface{}{
+			map[string]interface{}{
+				"target_cluster_id": targetClusterID,
+				"logs":              logs,
+				"metrics":           metrics,
+			},
+		}
+	}
+
+	cases := []struct {
+		name               string
+		monitoringInfo     *ElasticsearchMonitoringInfo
+		previousMonitoring []interface{}
+		expected           []map[string]interface{}
+	}{
+		{"not monitored", &ElasticsearchMonitoringInfo{}, monitoring("1a2b3c", true, false), []map[string]interface{}{}},
+		{"no monitoring info", nil, nil, []map[string]interface{}{}},
+		{"same destination", &ElasticsearchMonitoringInfo{DestinationClusterIDs: []string{"1a2b3c"}}, monitoring("1a2b3c", false, true),
+			[]map[string]interface{}{{"target_cluster_id": "1a2b3c", "logs": false, "metrics": true}}},
+		{"destination changed outside of terraform", &ElasticsearchMonitoringInfo{DestinationClusterIDs: []string{"4d5e6f"}}, monitoring("1a2b3c", false, true),
+			[]map[string]interface{}{{"target_cluster_id": "4d5e6f", "logs": true, "metrics": true}}},
+		{"destination set outside of terraform", &ElasticsearchMonitoringInfo{DestinationClusterIDs: []string{"4d5e6f"}}, nil,
+			[]map[string]interface{}{{"target_cluster_id": "4d5e6f", "logs": true, "metrics": true}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual := flattenElasticsearchClusterMonitoring(c.monitoringInfo, c.previousMonitoring)
+			if !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestResourceElasticsearchClusterCustomizeDiffKibanaVersion(t *testing.T) {
+	cases := []struct {
+		name                     string
+		upgradeWithElasticsearch bool
+		kibanaVersion            string
+		expectedError            string
+	}{
+		{"same version", false, "7.2.0", ""},
+		{"older version", false, "7.1.0", ""},
+		{"newer version", false, "7.3.0", "kibana version 7.3.0 cannot be newer than elasticsearch version 7.2.0"},
+		{"newer version following elasticsearch", true, "7.3.0", ""},
+		{"unknown version", false, config.UnknownVariableValue, ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rawConfig, err := config.NewRawConfig(map[string]interface{}{
+				"cluster_name": "logging",
+				"plan": []interface{}{
+					map[string]interface{}{
+						"elasticsearch": []interface{}{
+							map[string]interface{}{"version": "7.2.0"},
+						},
+					},
+				},
+				"kibana": []interface{}{
+					map[string]interface{}{
+						"upgrade_with_elasticsearch": c.upgradeWithElasticsearch,
+						"plan": []interface{}{
+							map[string]interface{}{
+								"kibana": []interface{}{
+									map[string]interface{}{"version": c.kibanaVersion},
+								},
+							},
+						},
+					},
+				},
+			})
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			_, err = schema.InternalMap(resourceElasticsearchCluster().Schema).Diff(nil, terraform.NewResourceConfig(rawConfig),
+				resourceElasticsearchClusterCustomizeDiffKibanaVersion, nil, true)
+			if c.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+				t.Errorf("expected error containing %q, got %v", c.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestResourceElasticsearchClusterCustomizeDiffMonitoring(t *testing.T) {
+	cases := []struct {
+		name        string
+		monitoring  map[string]interface{}
+		expectError bool
+	}{
+		{"defaults", map[string]interface{}{"target_cluster_id": "1a2b3c"}, false},
+		{"logs only", map[string]interface{}{"target_cluster_id": "1a2b3c", "metrics": false}, false},
+		{"metrics only", map[string]interface{}{"target_cluster_id": "1a2b3c", "logs": false}, false},
+		{"neither logs nor metrics", map[string]interface{}{"target_cluster_id": "1a2b3c", "logs": false, "metrics": false}, true},
+		{"unknown logs", map[string]interface{}{"target_cluster_id": "1a2b3c", "logs": config.UnknownVariableValue, "metrics": false}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rawConfig, err := config.NewRawConfig(map[string]interface{}{
+				"monitoring": []interface{}{c.monitoring},
+			})
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			_, err = schema.InternalMap(resourceElasticsearchCluster().Schema).Diff(nil, terraform.NewResourceConfig(rawConfig),
+				resourceElasticsearchClusterCustomizeDiffMonitoring, nil, true)
+			if c.expectError && err == nil {
+				t.Errorf("expected an error")
+			} else if !c.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestResourceElasticsearchClusterCustomizeDiffTopology_unknownValues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"errors": [{"code": "root.unauthorized.rbac"}]}`)
+	}))
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+
+	// The master-eligible element is only enabled when all its values are known.
+	topologyElement := func(key string, value interface{}) map[string]interface{} {
+		element := map[string]interface{}{
+			"memory_per_node":     1024,
+			"node_count_per_zone": 1,
+			"zone_count":          2,
+			"node_type": []interface{}{
+				map[string]interface{}{"data": true, "master": true},
+			},
+		}
+		element[key] = value
+
+		return element
+	}
+
+	cases := []struct {
+		name          string
+		element       map[string]interface{}
+		expectedError string
+	}{
+		{"known values", topologyElement("zone_count", 2), "Use an odd number of zones"},
+		{"unknown memory_per_node", topologyElement("memory_per_node", config.UnknownVariableValue), ""},
+		{"unknown node_count_per_zone", topologyElement("node_count_per_zone", config.UnknownVariableValue), ""},
+		{"unknown zone_count", topologyElement("zone_count", config.UnknownVariableValue), ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rawConfig, err := config.NewRawConfig(map[string]interface{}{
+				"plan": []interface{}{
+					map[string]interface{}{
+						"cluster_topology": []interface{}{c.element},
+						"elasticsearch": []interface{}{
+							map[string]interface{}{"version": "7.2.0"},
+						},
+					},
+				},
+			})
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			_, err = schema.InternalMap(resourceElasticsearchCluster().Schema).Diff(nil, terraform.NewResourceConfig(rawConfig),
+				resourceElasticsearchClusterCustomizeDiffTopology, client, true)
+			if c.expectedError == "" {
+				if err != nil {
+					t.Errorf("expected the topology validation to be skipped, got %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+				t.Errorf("expected error containing %q, got %v", c.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestResourceElasticsearchClusterRead_remoteClusters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		switch r.URL.Path {
+		case elasticsearchResource + "/0a0b0c":
+			fmt.Fprint(w, `{
+				"cluster_id": "0a0b0c",
+				"cluster_name": "search",
+				"status": "started",
+				"plan_info": {"current": {"plan": {"elasticsearch": {"version": "7.2.0"}, "cluster_topology": []}}}
+			}`)
+		case elasticsearchResource + "/0a0b0c/ccs/settings":
+			fmt.Fprint(w, `{"remotes": [{"alias": "logging", "elasticsearch_id": "1a2b3c", "skip_unavailable": false}]}`)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+
+	// The remote cluster was added outside of Terraform, so the state has no remote clusters.
+	d := resourceElasticsearchCluster().Data(nil)
+	d.SetId("0a0b0c")
+
+	err := resourceElasticsearchClusterRead(d, client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{"alias": "logging", "target_cluster_id": "1a2b3c", "skip_unavailable": false},
+	}
+	if actual := d.Get("remote_clusters").([]interface{}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected remote_clusters %v, got %v", expected, actual)
+	}
+}
+
+func TestResourceElasticsearchClusterRead_associatedKibanaCluster(t *testing.T) {
+	var kibanaRequests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		switch {
+		case r.URL.Path == elasticsearchResource+"/1a2b3c":
+			fmt.Fprint(w, `{
+				"cluster_id": "1a2b3c",
+				"cluster_name": "logging",
+				"status": "started",
+				"associated_kibana_clusters": [{"kibana_id": "4d5e6f", "enabled": true}],
+				"plan_info": {"current": {"plan": {"elasticsearch": {"version": "7.2.0"}, "cluster_topology": []}}}
+			}`)
+		case strings.HasPrefix(r.URL.Path, kibanaResource):
+			kibanaRequests = append(kibanaRequests, r.URL.Path)
+			fmt.Fprint(w, `{"cluster_id": "4d5e6f", "status": "started"}`)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+
+	cases := []struct {
+		name                    string
+		kibanaClusterID         string
+		expectedKibanaClusterID string
+		expectedKibanaRequests  int
+	}{
+		{"Kibana cluster not managed by the resource", "", "", 0},
+		{"Kibana cluster created or imported by the resource", "4d5e6f", "4d5e6f", 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			kibanaRequests = nil
+
+			d := resourceElasticsearchCluster().Data(nil)
+			d.SetId("1a2b3c")
+			d.Set("kibana_cluster_id", c.kibanaClusterID)
+
+			err := resourceElasticsearchClusterRead(d, client)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if actual := d.Get("kibana_cluster_id").(string); actual != c.expectedKibanaClusterID {
+				t.Errorf("expected kibana_cluster_id %q, got %q", c.expectedKibanaClusterID, actual)
+			}
+
+			if len(kibanaRequests) != c.expectedKibanaRequests {
+				t.Errorf("expected %d Kibana cluster requests, got %v", c.expectedKibanaRequests, kibanaRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/structure"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func resourceElasticsearchKeystoreSetting() *schema.Resource {
+	return &schema.Resource{
+		Create:        resourceElasticsearchKeystoreSettingCreate,
+		Read:          resourceElasticsearchKeystoreSettingRead,
+		Update:        resourceElasticsearchKeystoreSettingUpdate,
+		Delete:        resourceElasticsearchKeystoreSettingDelete,
+		CustomizeDiff: resourceElasticsearchKeystoreSettingCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			State: resourceElasticsearchKeystoreSettingImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"elasticsearch_cluster_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The ID of the Elasticsearch cluster whose keystore contains the setting.",
+				ForceNew:    true,
+				Required:    true,
+			},
+			"setting_name": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The name of the secure setting, such as s3.client.default.access_key.",
+				ForceNew:    true,
+				Required:    true,
+			},
+			"value": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "The string value of the secure setting. Conflicts with value_json.",
+				ForceNew:      false,
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"value_json"},
+			},
+			"value_json": &schema.Schema{
+				Type:             schema.TypeString,
+				Description:      "The JSON object value of the secure setting, such as the contents of a GCS service account file. Conflicts with value.",
+				ForceNew:         false,
+				Optional:         true,
+				Sensitive:        true,
+				ConflictsWith:    []string{"value"},
+				ValidateFunc:     validation.ValidateJsonString,
+				DiffSuppressFunc: structure.SuppressJsonDiff,
+			},
+			"as_file": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether the value is stored in the keystore as a file rather than a string, as required by settings such as gcs.client.default.credentials_file. The default is false.",
+				ForceNew:    false,
+				Optional:    true,
+				Default:     false,
+			},
+		},
+	}
+}
+
+func resourceElasticsearchKeystoreSettingCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ECEClient)
+
+	clusterID := d.Get("elasticsearch_cluster_id").(string)
+	settingName := d.Get("setting_name").(string)
+	log.Printf("[DEBUG] Creating keystore setting %s for elasticsearch cluster ID: %s\n", settingName, clusterID)
+
+	err := updateElasticsearchKeystoreSetting(client, d)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(clusterID + ":" + settingName)
+
+	return resourceElasticsearchKeystoreSettingRead(d, meta)
+}
+
+func resourceElasticsearchKeystoreSettingRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ECEClient)
+
+	clusterID, settingName, err := parseElasticsearchKeystoreSettingID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Reading keystore setting %s for elasticsearch cluster ID: %s\n", settingName, clusterID)
+
+	resp, err := client.GetElasticsearchClusterKeystore(clusterID)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == 404 {
+		log.Printf("[DEBUG] Elasticsearch cluster ID not found: %s\n", clusterID)
+		d.SetId("")
+		return nil
+	}
+
+	var keystoreContents KeystoreContents
+	err = json.NewDecoder(resp.Body).Decode(&keystoreContents)
+	if err != nil {
+		return err
+	}
+
+	// Values are never returned by ECE, so only the existence of the setting and as_file are refreshed.
+	secret, ok := keystoreContents.Secrets[settingName]
+	if !ok {
+		log.Printf("[DEBUG] Keystore setting %s not found for elasticsearch cluster ID: %s\n", settingName, clusterID)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("elasticsearch_cluster_id", clusterID)
+	d.Set("setting_name", settingName)
+
+	if secret.AsFile != nil {
+		d.Set("as_file", *secret.AsFile)
+	}
+
+	return nil
+}
+
+func resourceElasticsearchKeystoreSettingUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ECEClient)
+
+	log.Printf("[DEBUG] Updating keystore setting ID: %s\n", d.Id())
+
+	err := updateElasticsearchKeystoreSetting(client, d)
+	if err != nil {
+		return err
+	}
+
+	return resourceElasticsearchKeystoreSettingRead(d, meta)
+}
+
+func resourceElasticsearchKeystoreSettingDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ECEClient)
+
+	clusterID, settingName, err := parseElasticsearchKeystoreSettingID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Deleting keystore setting %s for elasticsearch cluster ID: %s\n", settingName, clusterID)
+
+	// Deletion is idempotent, so a setting of a cluster that no longer exists is treated as deleted.
+	resp, err := client.GetElasticsearchCluster(clusterID)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == 404 {
+		log.Printf("[DEBUG] Elasticsearch cluster ID not found, treating keystore setting as deleted: %s\n", clusterID)
+		d.SetId("")
+		return nil
+	}
+
+	// A secret without a value removes the setting from the keystore.
+	keystoreContents := KeystoreContents{
+		Secrets: map[string]KeystoreSecret{
+			settingName: KeystoreSecret{},
+		},
+	}
+
+	_, err = client.UpdateElasticsearchClusterKeystore(clusterID, keystoreContents)
+	if err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// resourceElasticsearchKeystoreSettingCustomizeDiff confirms at plan time that exactly one of value or value_json is
+// set. Values that are not known until apply are checked when the setting is created or updated.
+func resourceElasticsearchKeystoreSettingCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("value") || !d.NewValueKnown("value_json") {
+		return nil
+	}
+
+	if d.Get("value").(string) == "" && d.Get("value_json").(string) == "" {
+		return fmt.Errorf("one of value or value_json must be set")
+	}
+
+	return nil
+}
+
+// resourceElasticsearchKeystoreSettingImport imports a keystore setting using an ID of the form
+// <cluster_id>:<setting_name>. The value of the setting cannot be imported.
+func resourceElasticsearchKeystoreSettingImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	clusterID, settingName, err := parseElasticsearchKeystoreSettingID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set("elasticsearch_cluster_id", clusterID)
+	d.Set("setting_name", settingName)
+	d.Set("as_file", false)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func expandKeystoreSecret(d *schema.ResourceData) (*KeystoreSecret, error) {
+	asFile := d.Get("as_file").(bool)
+	secret := &KeystoreSecret{
+		AsFile: &asFile,
+	}
+
+	if v, ok := d.GetOk("value_json"); ok {
+		value, err := structure.ExpandJsonFromString(v.(string))
+		if err != nil {
+			return nil, fmt.Errorf("value_json is not valid JSON: %v", err)
+		}
+		secret.Value = value
+	} else if v, ok := d.GetOk("value"); ok {
+		secret.Value = v.(string)
+	} else {
+		return nil, fmt.Errorf("one of value or value_json must be set")
+	}
+
+	return secret, nil
+}
+
+func parseElasticsearchKeystoreSettingID(id string) (clusterID string, settingName string, err error) {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("%q: keystore setting ID must be of the form <cluster_id>:<setting_name>", id)
+	}
+
+	return parts[0], parts[1], nil
+}
+
+func updateElasticsearchKeystoreSetting(client *ECEClient, d *schema.ResourceData) error {
+	secret, err := expandKeystoreSecret(d)
+	if err != nil {
+		return err
+	}
+
+	keystoreContents := KeystoreContents{
+		Secrets: map[string]KeystoreSecret{
+			d.Get("setting_name").(string): *secret,
+		},
+	}
+
+	clusterID := d.Get("elasticsearch_cluster_id").(string)
+	_, err = client.UpdateElasticsearchClusterKeystore(clusterID, keystoreContents)
+	return err
+}
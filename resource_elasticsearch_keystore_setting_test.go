@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestParseElasticsearchKeystoreSettingID(t *testing.T) {
+	cases := []struct {
+		id                  string
+		expectedClusterID   string
+		expectedSettingName string
+		expectError         bool
+	}{
+		{"1a2b3c:s3.client.default.access_key", "1a2b3c", "s3.client.default.access_key", false},
+		{"1a2b3c:setting:with:colons", "1a2b3c", "setting:with:colons", false},
+		{"1a2b3c", "", "", true},
+		{"1a2b3c:", "", "", true},
+		{":s3.client.default.access_key", "", "", true},
+		{"", "", "", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.id, func(t *testing.T) {
+			clusterID, settingName, err := parseElasticsearchKeystoreSettingID(c.id)
+			if c.expectError {
+				if err == nil {
+					t.Errorf("expected an error, got (%q, %q)", clusterID, settingName)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if clusterID != c.expectedClusterID || settingName != c.expectedSettingName {
+				t.Errorf("expected (%q, %q), got (%q, %q)", c.expectedClusterID, c.expectedSettingName, clusterID, settingName)
+			}
+		})
+	}
+}
+
+func TestResourceElasticsearchKeystoreSettingCustomizeDiff(t *testing.T) {
+	cases := []struct {
+		name        string
+		raw         map[string]interface{}
+		expectError bool
+	}{
+		{"value", map[string]interface{}{"value": "secret"}, false},
+		{"value_json", map[string]interface{}{"value_json": `{"type": "service_account"}`}, false},
+		{"neither value nor value_json", map[string]interface{}{}, true},
+		{"unknown value", map[string]interface{}{"value": config.UnknownVariableValue}, false},
+	}
+
+	r := resourceElasticsearchKeystoreSetting()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.raw["elasticsearch_cluster_id"] = "1a2b3c"
+			c.raw["setting_name"] = "s3.client.default.access_key"
+
+			rawConfig, err := config.NewRawConfig(c.raw)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			_, err = schema.InternalMap(r.Schema).Diff(nil, terraform.NewResourceConfig(rawConfig), r.CustomizeDiff, nil, true)
+			if c.expectError && err == nil {
+				t.Errorf("expected an error")
+			} else if !c.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestResourceElasticsearchKeystoreSettingDelete_clusterNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors": [{"code": "clusters.cluster_not_found"}]}`)
+	}))
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+	d := resourceElasticsearchKeystoreSetting().Data(nil)
+	d.SetId("1a2b3c:s3.client.default.access_key")
+
+	err := resourceElasticsearchKeystoreSettingDelete(d, client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d.Id() != "" {
+		t.Errorf("expected the resource ID to be cleared, got %q", d.Id())
+	}
+}
+
+func TestUpdateElasticsearchKeystoreSetting_clusterNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors": [{"code": "clusters.cluster_not_found"}]}`)
+	}))
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+	d := schema.TestResourceDataRaw(t, resourceElasticsearchKeystoreSetting().Schema, map[string]interface{}{
+		"elasticsearch_cluster_id": "1a2b3c",
+		"setting_name":             "s3.client.default.access_key",
+		"value":                    "secret",
+	})
+
+	err := updateElasticsearchKeystoreSetting(client, d)
+	if err == nil || !strings.Contains(err.Error(), "clusters.cluster_not_found") {
+		t.Errorf("expected cluster not found error, got %v", err)
+	}
+}
+
+func TestResourceElasticsearchKeystoreSettingDelete(t *testing.T) {
+	var keystoreRequests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		switch r.URL.Path {
+		case elasticsearchResource + "/1a2b3c":
+			fmt.Fprint(w, `{"cluster_id": "1a2b3c"}`)
+		case elasticsearchResource + "/1a2b3c/keystore":
+			keystoreRequests = append(keystoreRequests, r.Method)
+			fmt.Fprint(w, `{"secrets": {}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors": [{"code": "clusters.cluster_not_found"}]}`)
+		}
+	}))
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+	d := resourceElasticsearchKeystoreSetting().Data(nil)
+	d.SetId("1a2b3c:s3.client.default.access_key")
+
+	err := resourceElasticsearchKeystoreSettingDelete(d, client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(keystoreRequests) != 1 || keystoreRequests[0] != "PATCH" {
+		t.Errorf("expected the setting to be removed from the keystore, got requests %v", keystoreRequests)
+	}
+
+	if d.Id() != "" {
+		t.Errorf("expected the resource ID to be cleared, got %q", d.Id())
+	}
+}
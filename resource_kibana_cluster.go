@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceKibanaCluster() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKibanaClusterCreate,
+		Read:   resourceKibanaClusterRead,
+		Update: resourceKibanaClusterUpdate,
+		Delete: resourceKibanaClusterDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"elasticsearch_cluster_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The ID of the Elasticsearch cluster that the Kibana cluster connects to.",
+				ForceNew:    true,
+				Required:    true,
+			},
+			"cluster_name": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The name of the Kibana cluster.",
+				ForceNew:    false,
+				Optional:    true,
+				Computed:    true,
+			},
+			"plan": {
+				Type:        schema.TypeList,
+				Description: "The plan for the Kibana cluster.",
+				ForceNew:    false,
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: kibanaClusterPlanSchema(&schema.Schema{
+						Type:        schema.TypeString,
+						Description: "The version of the Kibana cluster. The default is the version of the Elasticsearch cluster when the Kibana cluster is created.",
+						ForceNew:    false,
+						Optional:    true,
+						Computed:    true,
+					}),
+				},
+			},
+			"https_endpoint": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The HTTPS URL for the Kibana cluster, including port.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceKibanaClusterCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ECEClient)
+
+	elasticsearchClusterID := d.Get("elasticsearch_cluster_id").(string)
+	log.Printf("[DEBUG] Creating Kibana cluster for elasticsearch cluster ID: %s\n", elasticsearchClusterID)
+
+	kibanaPlan, err := expandStandaloneKibanaClusterPlan(client, d)
+	if err != nil {
+		return err
+	}
+
+	kibanaRequest := CreateKibanaRequest{
+		ClusterName:            d.Get("cluster_name").(string),
+		ElasticsearchClusterID: elasticsearchClusterID,
+		Plan:                   kibanaPlan,
+	}
+
+	crudResponse, err := client.CreateKibanaCluster(kibanaRequest)
+	if err != nil {
+		return err
+	}
+
+	kibanaClusterID := crudResponse.KibanaClusterID
+	log.Printf("[DEBUG] Created Kibana cluster ID: %s\n", kibanaClusterID)
+
+	err = client.WaitForKibanaClusterStatus(kibanaClusterID, "started", false)
+	if err != nil {
+		return err
+	}
+
+	// Confirm that the Kibana creation plan was successfully applied.
+	err = validateKibanaClusterPlanActivity(client, kibanaClusterID)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(kibanaClusterID)
+
+	return resourceKibanaClusterRead(d, meta)
+}
+
+func resourceKibanaClusterRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ECEClient)
+
+	kibanaClusterID := d.Id()
+	log.Printf("[DEBUG] Reading Kibana cluster information for cluster ID: %s\n", kibanaClusterID)
+
+	resp, err := client.GetKibanaCluster(kibanaClusterID)
+	if err != nil {
+		return err
+	}
+
+	// If the resource does not exist, inform Terraform. We want to immediately
+	// return here to prevent further processing.
+	if resp.StatusCode == 404 {
+		log.Printf("[DEBUG] Kibana cluster ID not found: %s\n", kibanaClusterID)
+		d.SetId("")
+		return nil
+	}
+
+	var kibanaInfo KibanaClusterInfo
+	err = json.NewDecoder(resp.Body).Decode(&kibanaInfo)
+	if err != nil {
+		return err
+	}
+
+	d.Set("elasticsearch_cluster_id", kibanaInfo.ElasticsearchCluster.ElasticsearchID)
+	d.Set("cluster_name", kibanaInfo.ClusterName)
+	d.Set("https_endpoint", flattenClusterEndpoint("https", kibanaInfo.Metadata))
+
+	plan := flattenKibanaClusterPlan(kibanaInfo.PlanInfo.Current.Plan)
+	log.Printf("[DEBUG] Setting Kibana cluster plan: %v\n", plan)
+	return d.Set("plan", plan)
+}
+
+func resourceKibanaClusterUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ECEClient)
+
+	kibanaClusterID := d.Id()
+	log.Printf("[DEBUG] Updating Kibana cluster ID: %s\n", kibanaClusterID)
+
+	d.Partial(true)
+
+	if d.HasChange("cluster_name") {
+		metadata := ClusterMetadataSettings{
+			ClusterName: d.Get("cluster_name").(string),
+		}
+
+		_, err := client.UpdateKibanaClusterMetadata(kibanaClusterID, metadata)
+		if err != nil {
+			return err
+		}
+	}
+
+	d.SetPartial("cluster_name")
+
+	if d.HasChange("plan") {
+		kibanaPlan, err := expandStandaloneKibanaClusterPlan(client, d)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.UpdateKibanaCluster(kibanaClusterID, kibanaPlan)
+		if err != nil {
+			return err
+		}
+
+		// Wait for the cluster plan to be initiated.
+		duration := time.Duration(5) * time.Second // 5 seconds
+		time.Sleep(duration)
+
+		err = client.WaitForKibanaClusterStatus(kibanaClusterID, "started", false)
+		if err != nil {
+			return err
+		}
+
+		// Confirm that the Kibana update plan was successfully applied.
+		err = validateKibanaClusterPlanActivity(client, kibanaClusterID)
+		if err != nil {
+			return err
+		}
+	}
+
+	d.Partial(false)
+
+	return resourceKibanaClusterRead(d, meta)
+}
+
+func resourceKibanaClusterDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ECEClient)
+
+	kibanaClusterID := d.Id()
+	log.Printf("[DEBUG] Deleting Kibana cluster ID: %s\n", kibanaClusterID)
+
+	_, err := client.DeleteKibanaCluster(kibanaClusterID)
+	if err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// expandStandaloneKibanaClusterPlan returns the Kibana cluster plan from the resource inputs. The version of the
+// Elasticsearch cluster is used when no Kibana version is set, and Kibana cannot be newer than Elasticsearch.
+func expandStandaloneKibanaClusterPlan(client *ECEClient, d *schema.ResourceData) (*KibanaClusterPlan, error) {
+	kibanaPlan := DefaultKibanaClusterPlan()
+
+	err := expandKibanaClusterPlan(kibanaPlan, d.Get("plan"))
+	if err != nil {
+		return nil, err
+	}
+
+	elasticsearchVersion, err := getElasticsearchClusterVersion(client, d.Get("elasticsearch_cluster_id").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	if kibanaPlan.Kibana.Version == "" {
+		kibanaPlan.Kibana.Version = elasticsearchVersion
+	}
+
+	// The version is checked against the current Elasticsearch version when the plan is applied, rather than at plan
+	// time, since the Elasticsearch cluster may be upgraded earlier in the same apply. Nothing is changed if it fails.
+	err = validateVersionNotNewerThanElasticsearch("kibana", kibanaPlan.Kibana.Version, elasticsearchVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return kibanaPlan, nil
+}
+
+// getElasticsearchClusterVersion returns the version in the current plan of an Elasticsearch cluster.
+func getElasticsearchClusterVersion(client *ECEClient, clusterID string) (string, error) {
+	resp, err := client.GetElasticsearchClusterPlan(clusterID)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode == 404 {
+		return "", fmt.Errorf("%q: elasticsearch cluster ID was not found", clusterID)
+	}
+
+	var clusterPlan ElasticsearchClusterPlan
+	err = json.NewDecoder(resp.Body).Decode(&clusterPlan)
+	if err != nil {
+		return "", err
+	}
+
+	return clusterPlan.Elasticsearch.Version, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func testElasticsearchClusterPlanServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		switch r.URL.Path {
+		case elasticsearchResource + "/1a2b3c/plan":
+			fmt.Fprint(w, `{"elasticsearch": {"version": "7.2.0"}, "cluster_topology": []}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors": [{"code": "clusters.cluster_not_found"}]}`)
+		}
+	}))
+}
+
+func TestGetElasticsearchClusterVersion(t *testing.T) {
+	server := testElasticsearchClusterPlanServer()
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+
+	elasticsearchVersion, err := getElasticsearchClusterVersion(client, "1a2b3c")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if elasticsearchVersion != "7.2.0" {
+		t.Errorf("expected version 7.2.0, got %s", elasticsearchVersion)
+	}
+
+	_, err = getElasticsearchClusterVersion(client, "4d5e6f")
+	if err == nil || !strings.Contains(err.Error(), "elasticsearch cluster ID was not found") {
+		t.Errorf("expected cluster not found error, got %v", err)
+	}
+}
+
+func TestExpandStandaloneKibanaClusterPlan(t *testing.T) {
+	server := testElasticsearchClusterPlanServer()
+	defer server.Close()
+
+	client := &ECEClient{HTTPClient: server.Client(), BaseURL: server.URL}
+
+	plan := func(kibanaVersion string) []interface{} {
+		return []interface{}{
+			map[string]interface{}{
+				"kibana": []interface{}{
+					map[string]interface{}{"version": kibanaVersion},
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		name                   string
+		elasticsearchClusterID string
+		plan                   []interface{}
+		expectedVersion        string
+		expectedError          string
+	}{
+		{"elasticsearch version", "1a2b3c", nil, "7.2.0", ""},
+		{"same version", "1a2b3c", plan("7.2.0"), "7.2.0", ""},
+		{"older version", "1a2b3c", plan("7.1.0"), "7.1.0", ""},
+		{"newer version", "1a2b3c", plan("7.3.0"), "", "cannot be newer than elasticsearch version 7.2.0"},
+		{"elasticsearch cluster not found", "4d5e6f", nil, "", "elasticsearch cluster ID was not found"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			raw := map[string]interface{}{
+				"elasticsearch_cluster_id": c.elasticsearchClusterID,
+			}
+			if c.plan != nil {
+				raw["plan"] = c.plan
+			}
+
+			d := schema.TestResourceDataRaw(t, resourceKibanaCluster().Schema, raw)
+
+			kibanaPlan, err := expandStandaloneKibanaClusterPlan(client, d)
+			if c.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+					t.Errorf("expected error containing %q, got %v", c.expectedError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if kibanaPlan.Kibana.Version != c.expectedVersion {
+				t.Errorf("expected Kibana version %s, got %s", c.expectedVersion, kibanaPlan.Kibana.Version)
+			}
+		})
+	}
+}
+
+func TestResourceKibanaClusterImport(t *testing.T) {
+	d := resourceKibanaCluster().Data(nil)
+	d.SetId("4d5e6f")
+
+	results, err := resourceKibanaCluster().Importer.State(d, &ECEClient{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(results) != 1 || results[0].Id() != "4d5e6f" {
+		t.Errorf("expected the Kibana cluster ID to be imported as is, got %v", results)
+	}
+}
@@ -3,16 +3,114 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/structure"
 )
 
 /*
 NOTE: This would need some refactoring to work for our needs, if it's even needed.
 */
 
+// resourceDataGetter is satisfied by both schema.ResourceData and schema.ResourceDiff.
+type resourceDataGetter interface {
+	Get(key string) interface{}
+}
+
+// suppressKibanaVersionDiff suppresses Kibana version differences when the Kibana version follows the
+// Elasticsearch version.
+func suppressKibanaVersionDiff(k, old, new string, d *schema.ResourceData) bool {
+	return kibanaVersionFollowsElasticsearch(d)
+}
+
+// suppressTopologyDefaultDiff returns a function that suppresses differences in a topology element value that is not
+// set in the configuration when the current value is the provider default, or when deployment_template_id is set and
+// the deployment template value is used instead.
+func suppressTopologyDefaultDiff(defaultValue string) schema.SchemaDiffSuppressFunc {
+	return func(k, old, new string, d *schema.ResourceData) bool {
+		if new == "" || new == "0" {
+			if usesDeploymentTemplate(d) {
+				return true
+			}
+
+			new = defaultValue
+		}
+
+		return old == new
+	}
+}
+
+// suppressTopologyZoneCountDiff suppresses differences in the zone count of a topology element when the element does
+// not set a zone count and the plan zone count, the deployment template value, or the provider default, in that
+// order, is used instead.
+func suppressTopologyZoneCountDiff(k, old, new string, d *schema.ResourceData) bool {
+	planZoneCount := d.Get(planLevelKey(k)).(int)
+	if planZoneCount == 0 {
+		if new == "" || new == "0" {
+			return suppressTopologyDefaultDiff(strconv.Itoa(DefaultElasticsearchClusterTopologyElement().ZoneCount))(k, old, new, d)
+		}
+
+		return old == new
+	}
+
+	// Elements without a zone count, in the configuration or in the state, are placed in the plan number of zones.
+	if old == "" || old == "0" {
+		old = strconv.Itoa(planZoneCount)
+	}
+
+	if new == "" || new == "0" {
+		new = strconv.Itoa(planZoneCount)
+	}
+
+	return old == new
+}
+
+// suppressTopologyElasticsearchDiff suppresses differences in the Elasticsearch settings of a topology element when the
+// effective values match, using the plan-level value for settings not set in the topology element.
+func suppressTopologyElasticsearchDiff(k, old, new string, d *schema.ResourceData) bool {
+	planValue := d.Get(planLevelKey(k)).(string)
+
+	if old == "" {
+		old = planValue
+	}
+
+	if new == "" {
+		new = planValue
+	}
+
+	if old == new {
+		return true
+	}
+
+	if strings.HasSuffix(k, "_json") {
+		return structure.SuppressJsonDiff(k, old, new, d)
+	}
+
+	return false
+}
+
+// usesDeploymentTemplate returns whether the resource uses a deployment template as the base for its plans. Resources
+// without a deployment_template_id attribute never do.
+func usesDeploymentTemplate(d resourceDataGetter) bool {
+	templateID, _ := d.Get("deployment_template_id").(string)
+	return templateID != ""
+}
+
+// planLevelKey returns the key of the plan-level value for the key of a topology element value. For example,
+// plan.0.cluster_topology.1.zone_count returns plan.0.zone_count.
+func planLevelKey(k string) string {
+	parts := strings.SplitN(k, ".", 5)
+	if len(parts) < 5 {
+		return k
+	}
+
+	return strings.Join(append(parts[:2], parts[4]), ".")
+}
+
 func diffSuppressClusterSettings(k, old, new string, d *schema.ResourceData) bool {
 	var oo, no interface{}
 	if err := json.Unmarshal([]byte(old), &oo); err != nil {
@@ -55,6 +153,67 @@ func normalizedClusterSettings(settings map[string]interface{}) map[string]inter
 	return f
 }
 
+// expandJSONObject parses a JSON object string into a map, treating an empty string as an empty object.
+func expandJSONObject(jsonString string) (map[string]interface{}, error) {
+	if jsonString == "" {
+		return make(map[string]interface{}), nil
+	}
+
+	return structure.ExpandJsonFromString(jsonString)
+}
+
+// flattenJSONObject returns a map as a JSON string, treating an empty map as an empty string.
+func flattenJSONObject(m map[string]interface{}) string {
+	if len(m) == 0 {
+		return ""
+	}
+
+	jsonString, err := structure.FlattenJsonToString(m)
+	if err != nil {
+		log.Printf("[DEBUG] Error flattening JSON object: %v\n", err)
+		return ""
+	}
+
+	return jsonString
+}
+
+// setSchemaDefaults sets the top-level attributes that have a default value in the schema to that value. This is used
+// for imported resources, whose configuration-only attributes are otherwise not set.
+func setSchemaDefaults(d *schema.ResourceData, schemaMap map[string]*schema.Schema) error {
+	for k, v := range schemaMap {
+		if v.Default == nil {
+			continue
+		}
+
+		err := d.Set(k, v.Default)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
 func flattenMap(m map[string]interface{}) map[string]interface{} {
 	f := make(map[string]interface{})
 	for k, v := range m {